@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+// encrypt prints enc: prefixed values for env.Parse to decrypt. The values to encrypt are read
+// from the arguments or, one per line, from stdin so that they do not show in the process list.
+//
+//	env encrypt -genkey > key
+//	echo 's3cr3t' | env encrypt -key-file key
+func encrypt(args []string) error {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	keyFile := fs.String("key-file", "", "file holding the base64 encoded key")
+	keyVar := fs.String("key-var", env.DefaultKeyVar, "environment variable holding the base64 encoded key")
+	genKey := fs.Bool("genkey", false, "print a new random key and exit")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *genKey {
+		k, err := env.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(k)
+		return nil
+	}
+
+	var ks string
+	switch {
+	case *keyFile != "":
+		b, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return err
+		}
+		ks = string(b)
+	default:
+		var ok bool
+		ks, ok = os.LookupEnv(*keyVar)
+		if !ok {
+			return fmt.Errorf("%w: set %s or use -key-file", env.ErrNoKey, *keyVar)
+		}
+	}
+	key, err := env.ParseKey(ks)
+	if err != nil {
+		return err
+	}
+
+	return encryptValues(key, fs.Args(), os.Stdin, os.Stdout)
+}
+
+func encryptValues(key []byte, values []string, in io.Reader, out io.Writer) error {
+	if len(values) == 0 {
+		sc := bufio.NewScanner(in)
+		for sc.Scan() {
+			values = append(values, strings.TrimRight(sc.Text(), "\r"))
+		}
+		if err := sc.Err(); err != nil {
+			return err
+		}
+	}
+	if len(values) == 0 {
+		return errors.New("nothing to encrypt")
+	}
+
+	for _, v := range values {
+		c, err := env.Encrypt(key, v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, c)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+// forwardedSignals are relayed to the child process.
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// execChild validates the environment against a schema, fills in the defaults and runs the command
+// with the resulting environment. The dotenv files are merged in order and the current environment
+// takes precedence over them. Signals are forwarded to the command and its exit code is returned.
+//
+//	env exec -schema schema.json -dotenv .env -- mycmd args
+func execChild(args []string) (int, error) {
+	fs := flag.NewFlagSet("exec", flag.ExitOnError)
+	sf := &sourceFlags{}
+	sf.register(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return exitError, err
+	}
+	if fs.NArg() == 0 {
+		return exitError, errors.New("expected a command to run after --")
+	}
+
+	vars, code, err := childEnv(sf)
+	if err != nil || code != exitOK {
+		return code, err
+	}
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...) //nolint:gosec // running the command is the point.
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = environ(vars)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals...)
+	defer signal.Stop(sigs)
+
+	err = cmd.Start()
+	if err != nil {
+		return exitError, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case s := <-sigs:
+				_ = cmd.Process.Signal(s)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+	var ee *exec.ExitError
+	if err != nil && !errors.As(err, &ee) {
+		return exitError, err
+	}
+	return exitCode(cmd.ProcessState), nil
+}
+
+// childEnv merges the dotenv files and the current environment, validates the result against
+// the schema and fills in the defaults. The variables tagged unset are left out, as Parse removes
+// them from the environment once read.
+func childEnv(sf *sourceFlags) (map[string]string, int, error) {
+	dotenv := sf.dotenv
+	sf.dotenv = nil
+	s, vars, err := sf.load()
+	if err != nil {
+		return nil, exitError, err
+	}
+
+	merged, err := env.LoadDotenv(dotenv...)
+	if err != nil {
+		return nil, exitError, err
+	}
+	for k, v := range vars {
+		merged[k] = v
+	}
+
+	code := exitOK
+	for _, v := range s.Resolve(merged, sf.profile) {
+		switch {
+		case v.Err != nil:
+			fmt.Fprintln(os.Stderr, v.Err)
+			code = exitInvalid
+		case v.Report.Source == env.SourceDefault:
+			merged[v.Var.Name] = v.Value
+		}
+	}
+	for _, v := range s.Vars {
+		if !v.Unset {
+			continue
+		}
+		for _, n := range v.Names() {
+			delete(merged, n)
+		}
+	}
+	return merged, code, nil
+}
+
+func environ(vars map[string]string) []string {
+	ee := make([]string, 0, len(vars))
+	for k, v := range vars {
+		ee = append(ee, k+"="+v)
+	}
+	sort.Strings(ee)
+	return ee
+}
+
+// exitCode returns the exit code of the child, or 128 + the signal number when it was killed
+// by a signal, as shells do.
+func exitCode(ps *os.ProcessState) int {
+	if ws, ok := ps.Sys().(interface {
+		Signaled() bool
+		Signal() syscall.Signal
+	}); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return ps.ExitCode()
+}
@@ -0,0 +1,99 @@
+package main_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// build builds the env command into a temporary directory.
+func build(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "env")
+	out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	return bin
+}
+
+func TestExec(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the command")
+	}
+	bin := build(t)
+
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "schema.json")
+	err := os.WriteFile(schema, []byte(`{"vars": [
+		{"name": "EXEC_HOST", "field": "Host", "type": "string", "default": "localhost"},
+		{"name": "EXEC_TOKEN", "field": "Token", "type": "string", "aliases": ["EXEC_OLD_TOKEN"], "unset": true},
+		{"name": "EXEC_PORT", "field": "Port", "type": "int"}
+	]}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		vars   []string
+		script string
+		code   int
+		out    string
+	}{
+		{
+			name:   "defaults and unset",
+			vars:   []string{"EXEC_TOKEN=t", "EXEC_OLD_TOKEN=o"},
+			script: `echo "$EXEC_HOST|${EXEC_TOKEN-unset}|${EXEC_OLD_TOKEN-unset}"`,
+			out:    "localhost|unset|unset\n",
+		},
+		{
+			name:   "environment",
+			vars:   []string{"EXEC_HOST=db"},
+			script: `echo "$EXEC_HOST"`,
+			out:    "db\n",
+		},
+		{
+			name:   "exit code",
+			script: `exit 3`,
+			code:   3,
+		},
+		{
+			name:   "killed",
+			script: `kill -TERM $$`,
+			code:   128 + 15,
+		},
+		{
+			name:   "invalid",
+			vars:   []string{"EXEC_PORT=http"},
+			script: `echo ran`,
+			code:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(bin, "exec", "-schema", schema, "--", "sh", "-c", tt.script)
+			cmd.Env = append([]string{"PATH=" + os.Getenv("PATH")}, tt.vars...)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+			out, err := cmd.Output()
+
+			code := 0
+			var ee *exec.ExitError
+			if errors.As(err, &ee) {
+				code = ee.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.code {
+				t.Errorf("exit code %d, want %d, stderr:\n%s", code, tt.code, stderr.String())
+			}
+			if string(out) != tt.out {
+				t.Errorf("output %q, want %q", out, tt.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+// stringsFlag is a flag that can be repeated, eg -dotenv .env -dotenv .env.local.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// sourceFlags are the flags selecting the schema and the environment it is checked against.
+type sourceFlags struct {
+	schema  string
+	dotenv  stringsFlag
+	profile string
+}
+
+func (sf *sourceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&sf.schema, "schema", "", "JSON schema exported by env.Describe (required)")
+	fs.Var(&sf.dotenv, "dotenv", "dotenv file to use instead of the current environment, can be repeated")
+	fs.StringVar(&sf.profile, "profile", "", "active profile, read from "+env.DefaultProfileVar+" when not set")
+}
+
+func (sf *sourceFlags) load() (*env.Schema, map[string]string, error) {
+	if sf.schema == "" {
+		return nil, nil, errors.New("-schema is required")
+	}
+	f, err := os.Open(sf.schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	s, err := env.ReadSchema(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(sf.dotenv) == 0 {
+		return s, env.GetEnvVars(), nil
+	}
+	vars, err := env.LoadDotenv(sf.dotenv...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, vars, nil
+}
+
+// check validates the environment against a schema.
+//
+//	env check -schema schema.json -dotenv .env
+func check(args []string) (int, error) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	sf := &sourceFlags{}
+	sf.register(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return exitError, err
+	}
+
+	s, vars, err := sf.load()
+	if err != nil {
+		return exitError, err
+	}
+
+	code := exitOK
+	for _, v := range s.Resolve(vars, sf.profile) {
+		if v.Err != nil {
+			fmt.Fprintln(os.Stderr, v.Err)
+			code = exitInvalid
+			continue
+		}
+		if v.Report.Deprecated {
+			fmt.Fprintf(os.Stderr, "%s: deprecated name %s used\n", v.Var.Name, v.Report.Var)
+		}
+	}
+	return code, nil
+}
+
+// printConfig shows the effective configuration, secrets are masked.
+//
+//	env print -schema schema.json
+func printConfig(args []string) (int, error) {
+	fs := flag.NewFlagSet("print", flag.ExitOnError)
+	sf := &sourceFlags{}
+	sf.register(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return exitError, err
+	}
+
+	s, vars, err := sf.load()
+	if err != nil {
+		return exitError, err
+	}
+
+	code := exitOK
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVALUE\tSOURCE\tFIELD")
+	for _, v := range s.Resolve(vars, sf.profile) {
+		val := v.Value
+		if v.Var.Secret && val != "" {
+			val = "******"
+		}
+		src := string(v.Report.Source)
+		switch {
+		case v.Err != nil:
+			src = "error: " + v.Err.Error()
+			code = exitInvalid
+		case v.Report.Source == env.SourceEnv && v.Report.Var != v.Var.Name:
+			src = fmt.Sprintf("%s %s", src, v.Report.Var)
+		case v.Report.Profile != "":
+			src = fmt.Sprintf("%s (%s)", src, v.Report.Profile)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Var.Name, val, src, v.Var.Field)
+	}
+	return code, w.Flush()
+}
+
+// diff compares two dotenv files. Values of secret variables are masked when a schema is provided.
+//
+//	env diff -schema schema.json .env.staging .env.prod
+func diff(args []string) (int, error) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	schema := fs.String("schema", "", "JSON schema exported by env.Describe, used to mask secrets")
+	err := fs.Parse(args)
+	if err != nil {
+		return exitError, err
+	}
+	if fs.NArg() != 2 {
+		return exitError, errors.New("expected two dotenv files")
+	}
+
+	secrets := map[string]bool{}
+	if *schema != "" {
+		f, err := os.Open(*schema)
+		if err != nil {
+			return exitError, err
+		}
+		s, err := env.ReadSchema(f)
+		f.Close()
+		if err != nil {
+			return exitError, err
+		}
+		for _, v := range s.Vars {
+			for _, n := range v.Names() {
+				secrets[n] = v.Secret
+			}
+		}
+	}
+
+	a, err := env.LoadDotenv(fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+	b, err := env.LoadDotenv(fs.Arg(1))
+	if err != nil {
+		return exitError, err
+	}
+
+	if writeDiff(os.Stdout, a, b, secrets) {
+		return exitInvalid, nil
+	}
+	return exitOK, nil
+}
+
+// writeDiff writes the variables removed (-), added (+) and changed (~) between a and b. It reports
+// whether there are any differences.
+func writeDiff(w io.Writer, a, b map[string]string, secrets map[string]bool) bool {
+	names := map[string]struct{}{}
+	for k := range a {
+		names[k] = struct{}{}
+	}
+	for k := range b {
+		names[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for k := range names {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	show := func(k, v string) string {
+		if secrets[k] {
+			return "******"
+		}
+		return v
+	}
+
+	differ := false
+	for _, k := range sorted {
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inB:
+			fmt.Fprintf(w, "- %s=%s\n", k, show(k, av))
+		case !inA:
+			fmt.Fprintf(w, "+ %s=%s\n", k, show(k, bv))
+		case av != bv:
+			fmt.Fprintf(w, "~ %s: %s -> %s\n", k, show(k, av), show(k, bv))
+		default:
+			continue
+		}
+		differ = true
+	}
+	return differ
+}
+
+// example writes a .env.example listing every variable of a schema with its default.
+//
+//	env example -schema schema.json -o .env.example
+func example(args []string) (int, error) {
+	fs := flag.NewFlagSet("example", flag.ExitOnError)
+	schema := fs.String("schema", "", "JSON schema exported by env.Describe (required)")
+	out := fs.String("o", "", "output file, stdout by default")
+	err := fs.Parse(args)
+	if err != nil {
+		return exitError, err
+	}
+	if *schema == "" {
+		return exitError, errors.New("-schema is required")
+	}
+
+	f, err := os.Open(*schema)
+	if err != nil {
+		return exitError, err
+	}
+	s, err := env.ReadSchema(f)
+	f.Close()
+	if err != nil {
+		return exitError, err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		of, err := os.Create(*out)
+		if err != nil {
+			return exitError, err
+		}
+		defer of.Close()
+		w = of
+	}
+
+	return exitOK, writeExample(w, s)
+}
+
+func writeExample(w io.Writer, s *env.Schema) error {
+	for i, v := range s.Vars {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if v.Description != "" {
+			fmt.Fprintf(w, "# %s\n", v.Description)
+		}
+
+		notes := []string{v.Type}
+		if v.Required {
+			notes = append(notes, "required")
+		}
+		if v.Secret {
+			notes = append(notes, "secret")
+		}
+		if len(v.Aliases) > 0 {
+			notes = append(notes, "aliases "+strings.Join(v.Aliases, ", "))
+		}
+		if len(v.Deprecated) > 0 {
+			notes = append(notes, "deprecated "+strings.Join(v.Deprecated, ", "))
+		}
+		profiles := make([]string, 0, len(v.ProfileDefaults))
+		for p := range v.ProfileDefaults {
+			profiles = append(profiles, p)
+		}
+		sort.Strings(profiles)
+		for _, p := range profiles {
+			notes = append(notes, fmt.Sprintf("%s default %s", p, v.ProfileDefaults[p]))
+		}
+		fmt.Fprintf(w, "# %s\n", strings.Join(notes, ", "))
+
+		val := ""
+		if v.Default != nil && !v.Secret {
+			val = *v.Default
+		}
+		err := env.WriteDotenv(w, map[string]string{v.Name: val})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manifest writes Kubernetes or docker-compose manifests for a schema. The values are taken from
+// the dotenv files, not from the current environment, falling back to the defaults.
+//
+//	env manifest -schema schema.json -name app -dotenv .env.prod -format k8s
+func manifest(args []string) (int, error) {
+	fs := flag.NewFlagSet("manifest", flag.ExitOnError)
+	sf := &sourceFlags{}
+	sf.register(fs)
+	name := fs.String("name", "app", "name of the ConfigMap and Secret, or of the compose service")
+	format := fs.String("format", "k8s", "k8s (ConfigMap and Secret), container (env snippet), "+
+		"container-envfrom (envFrom snippet) or compose")
+	err := fs.Parse(args)
+	if err != nil {
+		return exitError, err
+	}
+
+	dotenv := sf.dotenv
+	sf.dotenv = nil
+	s, _, err := sf.load()
+	if err != nil {
+		return exitError, err
+	}
+	vars, err := env.LoadDotenv(dotenv...)
+	if err != nil {
+		return exitError, err
+	}
+
+	switch *format {
+	case "k8s":
+		err = s.WriteKubernetes(os.Stdout, *name, vars, sf.profile)
+	case "container":
+		err = s.WriteContainerEnv(os.Stdout, *name, false)
+	case "container-envfrom":
+		err = s.WriteContainerEnv(os.Stdout, *name, true)
+	case "compose":
+		err = s.WriteCompose(os.Stdout, *name, vars, sf.profile)
+	default:
+		return exitError, fmt.Errorf("unknown format %s", *format)
+	}
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, nil
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 
 	"github.com/tamarakaufler/go-and-reflect/env"
@@ -11,6 +15,8 @@ type User struct {
 	Name    string  `env:"USER_NAME" envDefault:"Lucien"`
 	Age     float32 `env:"USER_AGE" envDefault:"23.5"`
 	Address Address
+	// LogLevel has a default per profile, see APP_PROFILE.
+	LogLevel string `env:"USER_LOG_LEVEL" envDefault:"info" envDefault.dev:"debug" envDefault.prod:"warn"`
 
 	nationalInsurance string //nolint:structcheck,unused
 }
@@ -18,30 +24,130 @@ type User struct {
 type Address struct {
 	Street   string `env:"USER_ADDRESS_STREET,required"`
 	City     string `env:"USER_ADDRESS_CITY,required"`
-	Postcode string `env:"USER_ADDRESS_POSTCODE,required"`
+	Postcode string `env:"USER_ADDRESS_POSTCODE,required" envAliases:"USER_ADDRESS_ZIP" envDeprecated:"USER_ADDRESS_ZIP"`
 	LatLng   LatLng
 }
 
 type LatLng struct {
-	Lat float64 `env:"USER_ADDRESS_LAT" envDefault:"40.0000"`
-	Lng float64 `env:"USER_ADDRESS_LNG" envDefault:"-115.1111"`
+	Lat float64 `env:"USER_ADDRESS_LAT" envDefault:"40.0000" envDescription:"latitude of the address"`
+	Lng float64 `env:"USER_ADDRESS_LNG" envDefault:"-115.1111" envDescription:"longitude of the address"`
 }
 
+const usage = `Usage: env <command> [flags]
+
+Commands:
+  check     validate the environment or dotenv files against a schema exported by env.Describe
+  print     show the effective configuration with sources and masked secrets
+  diff      compare two dotenv files
+  example   write a .env.example for a schema
+  manifest  write Kubernetes ConfigMap/Secret, container env or docker-compose environment YAML
+  exec      run a command with the validated environment, eg env exec -schema s.json -- mycmd args
+  encrypt   encrypt values for env.Parse to decrypt
+  demo      parse the demo User struct, -schema prints its schema, -serve serves it
+
+Run env <command> -h for the command flags.
+`
+
+// exit codes.
+const (
+	exitOK      = 0
+	exitInvalid = 1 // check failed or diff found differences.
+	exitError   = 2 // usage or I/O error.
+)
+
 func main() {
-	os.Setenv("USER_NAME", "Rebecca")
-	os.Setenv("USER_ADDRESS_STREET", "16 St Mary's Close")
-	os.Setenv("USER_ADDRESS_CITY", "St Albans")
-	os.Setenv("USER_ADDRESS_POSTCODE", "AL3")
-	os.Setenv("USER_AGE", "45")
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitError)
+	}
+
+	var (
+		code int
+		err  error
+	)
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "check":
+		code, err = check(args)
+	case "print":
+		code, err = printConfig(args)
+	case "diff":
+		code, err = diff(args)
+	case "example":
+		code, err = example(args)
+	case "manifest":
+		code, err = manifest(args)
+	case "exec":
+		code, err = execChild(args)
+	case "encrypt":
+		err = encrypt(args)
+	case "demo":
+		err = demo(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %s\n\n%s", os.Args[1], usage)
+		os.Exit(exitError)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "env %s: %v\n", os.Args[1], err)
+		os.Exit(exitError)
+	}
+	os.Exit(code)
+}
+
+func demo(args []string) error {
+	fs := flag.NewFlagSet("demo", flag.ExitOnError)
+	schema := fs.Bool("schema", false, "print the schema of the demo User struct and exit")
+	serve := fs.String("serve", "", "serve the parsed configuration on this address, eg localhost:8081")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *schema {
+		s, err := env.Describe(&User{})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+
+	// the demo environment is served from a map, the process environment is left alone.
+	vars := env.Map{
+		"USER_NAME":           "Rebecca",
+		"USER_ADDRESS_STREET": "16 St Mary's Close",
+		"USER_ADDRESS_CITY":   "St Albans",
+		"USER_ADDRESS_ZIP":    "AL3",
+		"USER_AGE":            "45",
+		"APP_PROFILE":         "dev",
+	}
 
 	log.Println("######################### env ############################")
 
-	cfg := &User{}
+	report := &env.Report{}
 
-	err := env.Parse(cfg)
+	cfg, err := env.ParseAs[User](env.WithLookuper(vars), env.WithReport(report))
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	log.Printf(" After parsing: cfg ... %+v\n", cfg)
+
+	log.Println("######################### provenance ############################")
+	err = report.Print(os.Stdout)
+	if err != nil || *serve == "" {
+		return err
+	}
+
+	d := env.DebugHandler(&cfg, report)
+	err = d.Publish("config")
+	if err != nil {
+		return err
+	}
+	http.Handle("/debug/config", d)
+	log.Printf("serving the configuration on http://%s/debug/config and /debug/vars\n", *serve)
+	return http.ListenAndServe(*serve, nil) //nolint:gosec // local demo server.
 }
@@ -0,0 +1,72 @@
+package example
+
+import (
+	"net"
+	"net/url"
+	"time"
+)
+
+//go:generate go run github.com/tamarakaufler/go-and-reflect/cmd/envgen -type Config -output config_envgen.go
+
+type Port int
+
+type Config struct {
+	Name     string        `env:"APP_NAME" envDefault:"envgen" envDescription:"name of the service"`
+	Port     Port          `env:"APP_PORT" envDefault:"8080" envMin:"1" envMax:"65535"`
+	Debug    bool          `env:"APP_DEBUG"`
+	Timeout  time.Duration `env:"APP_TIMEOUT" envDefault:"5s" envDefault.prod:"30s"`
+	Ratio    *float64      `env:"APP_RATIO"`
+	Level    string        `env:"APP_LEVEL,notEmpty" envEnum:"debug,info,warn" envDefault:"info"`
+	Endpoint url.URL       `env:"APP_ENDPOINT" envDefault:"http://localhost"`
+	Bind     net.IP        `env:"APP_BIND" envDefault:"127.0.0.1"`
+	Token    string        `env:"APP_TOKEN,secret"`
+	Region   string        `env:"APP_REGION,noOverwrite" envDefault:"eu-west-1"`
+	DB       DB
+	Cache    *Cache
+
+	// shared pieces, their fields are promoted.
+	CommonHTTP `envPrefix:"PUBLIC_"`
+	*CommonDB  `envPrefix:"REPLICA_"`
+	limits
+
+	// Store is populated through the pointer it holds.
+	Store Store `envPrefix:"STORE_"`
+
+	internal int //nolint:structcheck,unused
+}
+
+type DB struct {
+	Host     string `env:"DB_HOST,required" envAliases:"DATABASE_HOST" envDeprecated:"DATABASE_HOST"`
+	Port     int32  `env:"DB_PORT" envDefault:"5432"`
+	Password string `env:"DB_PASSWORD,secret,required"`
+}
+
+type Cache struct {
+	Size int64 `env:"CACHE_SIZE" envDefault:"128"`
+}
+
+type CommonHTTP struct {
+	Addr         string        `env:"HTTP_ADDR" envDefault:":8080"`
+	ReadTimeout  time.Duration `env:"HTTP_READ_TIMEOUT" envDefault:"10s"`
+	WriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT"`
+}
+
+type CommonDB struct {
+	DSN      string `env:"DB_DSN,secret" envAliases:"DB_URL" envDeprecated:"DB_URL"`
+	MaxConns int    `env:"DB_MAX_CONNS" envDefault:"10"`
+}
+
+type limits struct {
+	RPS int `env:"LIMIT_RPS" envDefault:"100"`
+}
+
+type Store interface {
+	Kind() string
+}
+
+type S3Store struct {
+	Bucket string `env:"BUCKET,required"`
+	Region string `env:"REGION" envDefault:"eu-west-2"`
+}
+
+func (*S3Store) Kind() string { return "s3" }
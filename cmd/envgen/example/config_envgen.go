@@ -0,0 +1,283 @@
+// Code generated by envgen. DO NOT EDIT.
+
+package example
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+// LoadFromEnv populates c from the variables looked up with lookup, eg os.LookupEnv, following
+// the same rules as env.Parse without reflection.
+func (c *Config) LoadFromEnv(lookup func(string) (string, bool), opts ...envload.Option) error {
+	l, err := envload.NewLoader(lookup, opts...)
+	if err != nil {
+		return err
+	}
+
+	var fv *envload.FieldValue
+	fv, err = l.Value(&envgenVarsConfig[0], c.Name != "")
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		c.Name = fv.Value
+	}
+	fv, err = l.Value(&envgenVarsConfig[1], c.Port != 0)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := strconv.ParseInt(fv.Value, 0, 0)
+			if err != nil {
+				return fv.ParseError("Port")
+			}
+			c.Port = Port(v)
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[2], c.Debug)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := strconv.ParseBool(fv.Value)
+			if err != nil {
+				return fv.ParseError("Debug")
+			}
+			c.Debug = v
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[3], c.Timeout != 0)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := time.ParseDuration(fv.Value)
+			if err != nil {
+				return fv.ParseError("Timeout")
+			}
+			c.Timeout = v
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[4], c.Ratio != nil)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		if c.Ratio == nil {
+			c.Ratio = new(float64)
+		}
+		{
+			v, err := strconv.ParseFloat(fv.Value, 64)
+			if err != nil {
+				return fv.ParseError("Ratio")
+			}
+			*c.Ratio = v
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[5], c.Level != "")
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		c.Level = fv.Value
+	}
+	fv, err = l.Value(&envgenVarsConfig[6], c.Endpoint != (url.URL{}))
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := url.Parse(fv.Value)
+			if err != nil {
+				return fv.ParseError("Endpoint")
+			}
+			c.Endpoint = *v
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[7], c.Bind != nil)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		if ip := net.ParseIP(fv.Value); ip != nil {
+			c.Bind = ip
+		} else {
+			return fv.ParseError("Bind")
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[8], c.Token != "")
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		c.Token = fv.Value
+	}
+	fv, err = l.Value(&envgenVarsConfig[9], c.Region != "")
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		c.Region = fv.Value
+	}
+	fv, err = l.Value(&envgenVarsConfig[10], c.DB.Host != "")
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		c.DB.Host = fv.Value
+	}
+	fv, err = l.Value(&envgenVarsConfig[11], c.DB.Port != 0)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := strconv.ParseInt(fv.Value, 0, 32)
+			if err != nil {
+				return fv.ParseError("Port")
+			}
+			c.DB.Port = int32(v)
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[12], c.DB.Password != "")
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		c.DB.Password = fv.Value
+	}
+	if c.Cache != nil {
+		fv, err = l.Value(&envgenVarsConfig[13], c.Cache.Size != 0)
+		if err != nil {
+			return err
+		}
+		if fv != nil {
+			{
+				v, err := strconv.ParseInt(fv.Value, 0, 64)
+				if err != nil {
+					return fv.ParseError("Size")
+				}
+				c.Cache.Size = v
+			}
+		}
+	} else {
+		fv, err = l.Value(&envgenVarsConfig[14], false)
+		if err != nil {
+			return err
+		}
+		_ = fv
+	}
+	fv, err = l.Value(&envgenVarsConfig[15], c.CommonHTTP.Addr != "")
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		c.CommonHTTP.Addr = fv.Value
+	}
+	fv, err = l.Value(&envgenVarsConfig[16], c.CommonHTTP.ReadTimeout != 0)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := time.ParseDuration(fv.Value)
+			if err != nil {
+				return fv.ParseError("ReadTimeout")
+			}
+			c.CommonHTTP.ReadTimeout = v
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[17], c.CommonHTTP.WriteTimeout != 0)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := time.ParseDuration(fv.Value)
+			if err != nil {
+				return fv.ParseError("WriteTimeout")
+			}
+			c.CommonHTTP.WriteTimeout = v
+		}
+	}
+	if c.CommonDB == nil {
+		c.CommonDB = new(CommonDB)
+	}
+	fv, err = l.Value(&envgenVarsConfig[18], c.CommonDB.DSN != "")
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		c.CommonDB.DSN = fv.Value
+	}
+	fv, err = l.Value(&envgenVarsConfig[19], c.CommonDB.MaxConns != 0)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := strconv.ParseInt(fv.Value, 0, 0)
+			if err != nil {
+				return fv.ParseError("MaxConns")
+			}
+			c.CommonDB.MaxConns = int(v)
+		}
+	}
+	fv, err = l.Value(&envgenVarsConfig[20], c.limits.RPS != 0)
+	if err != nil {
+		return err
+	}
+	if fv != nil {
+		{
+			v, err := strconv.ParseInt(fv.Value, 0, 0)
+			if err != nil {
+				return fv.ParseError("RPS")
+			}
+			c.limits.RPS = int(v)
+		}
+	}
+	err = env.LoadInterface(l, c.Store, &envgenVarsConfig[21], "STORE_")
+	if err != nil {
+		return err
+	}
+	_ = fv
+	return nil
+}
+
+var envgenVarsConfig = [...]envload.Var{
+	{Field: "Name", Name: "APP_NAME", Type: "string", Default: envgenString("envgen"), Description: "name of the service"},
+	{Field: "Port", Name: "APP_PORT", Type: "int", Default: envgenString("8080"), Min: "1", Max: "65535"},
+	{Field: "Debug", Name: "APP_DEBUG", Type: "bool"},
+	{Field: "Timeout", Name: "APP_TIMEOUT", Type: "duration", Default: envgenString("5s"), ProfileDefaults: map[string]string{"prod": "30s"}},
+	{Field: "Ratio", Name: "APP_RATIO", Type: "float64"},
+	{Field: "Level", Name: "APP_LEVEL", Type: "string", Default: envgenString("info"), NotEmpty: true, Enum: []string{"debug", "info", "warn"}},
+	{Field: "Endpoint", Name: "APP_ENDPOINT", Type: "url", Default: envgenString("http://localhost")},
+	{Field: "Bind", Name: "APP_BIND", Type: "ip", Default: envgenString("127.0.0.1")},
+	{Field: "Token", Name: "APP_TOKEN", Type: "string", Secret: true},
+	{Field: "Region", Name: "APP_REGION", Type: "string", Default: envgenString("eu-west-1"), NoOverwrite: true},
+	{Field: "DB.Host", Name: "DB_HOST", Type: "string", Aliases: []string{"DATABASE_HOST"}, Deprecated: []string{"DATABASE_HOST"}, Required: true},
+	{Field: "DB.Port", Name: "DB_PORT", Type: "int32", Default: envgenString("5432")},
+	{Field: "DB.Password", Name: "DB_PASSWORD", Type: "string", Required: true, Secret: true},
+	{Field: "Cache.Size", Name: "CACHE_SIZE", Type: "int64", Default: envgenString("128")},
+	{Field: "Cache"},
+	{Field: "CommonHTTP.Addr", Name: "PUBLIC_HTTP_ADDR", Type: "string", Default: envgenString(":8080")},
+	{Field: "CommonHTTP.ReadTimeout", Name: "PUBLIC_HTTP_READ_TIMEOUT", Type: "duration", Default: envgenString("10s")},
+	{Field: "CommonHTTP.WriteTimeout", Name: "PUBLIC_HTTP_WRITE_TIMEOUT", Type: "duration"},
+	{Field: "CommonDB.DSN", Name: "REPLICA_DB_DSN", Type: "string", Aliases: []string{"REPLICA_DB_URL"}, Deprecated: []string{"REPLICA_DB_URL"}, Secret: true},
+	{Field: "CommonDB.MaxConns", Name: "REPLICA_DB_MAX_CONNS", Type: "int", Default: envgenString("10")},
+	{Field: "limits.RPS", Name: "LIMIT_RPS", Type: "int", Default: envgenString("100")},
+	{Field: "Store"},
+}
+
+func envgenString(s string) *string {
+	return &s
+}
@@ -0,0 +1,165 @@
+package example_test
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/cmd/envgen/example"
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+// TestLoadFromEnv checks that the loader generated by envgen behaves as env.Parse: both populate
+// a Config from the same environment and the values, errors and reports are compared.
+func TestLoadFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  env.Map
+		// err is part of the error both must return, empty when they must succeed.
+		err string
+		// check looks at the values populated when they succeed.
+		check func(t *testing.T, c *example.Config)
+	}{
+		{
+			name: "defaults",
+			env:  env.Map{"DB_HOST": "db", "DB_PASSWORD": "secret"},
+			check: func(t *testing.T, c *example.Config) {
+				want(t, "Port", c.Port, example.Port(8080))
+				want(t, "Timeout", c.Timeout, 5*time.Second)
+				want(t, "Region", c.Region, "us-east-1") // preset kept, noOverwrite.
+				want(t, "CommonHTTP.Addr", c.Addr, ":8080")
+				want(t, "CommonDB", c.CommonDB != nil, true) // nil embedded pointer allocated.
+				want(t, "limits.RPS", c.RPS, 100)
+			},
+		},
+		{
+			name: "prod profile, aliases, prefixes and pointers",
+			env: env.Map{
+				"APP_PROFILE":   "prod",
+				"APP_PORT":      "0x1f90",
+				"APP_DEBUG":     "true",
+				"APP_RATIO":     "0.25",
+				"APP_ENDPOINT":  "https://example.com/api",
+				"APP_BIND":      "::1",
+				"APP_TOKEN":     "t0k3n",
+				"DATABASE_HOST": "legacy-db",
+				"DB_PASSWORD":   "secret",
+				"CACHE_SIZE":    "64",
+
+				"PUBLIC_HTTP_ADDR":          ":443",
+				"PUBLIC_HTTP_WRITE_TIMEOUT": "1m",
+				"REPLICA_DB_URL":            "postgres://replica",
+				"LIMIT_RPS":                 "250",
+				"STORE_BUCKET":              "configs",
+			},
+			check: func(t *testing.T, c *example.Config) {
+				want(t, "Port", c.Port, example.Port(8080))
+				want(t, "Debug", c.Debug, true)
+				want(t, "Timeout", c.Timeout, 30*time.Second)
+				want(t, "Ratio", *c.Ratio, 0.25)
+				want(t, "Endpoint", c.Endpoint.Host, "example.com")
+				want(t, "Bind", c.Bind.String(), "::1")
+				want(t, "Token", c.Token, "t0k3n")
+				want(t, "DB.Host", c.DB.Host, "legacy-db")
+				want(t, "Cache.Size", c.Cache.Size, int64(64))
+				want(t, "CommonHTTP.Addr", c.Addr, ":443")
+				want(t, "CommonHTTP.WriteTimeout", c.WriteTimeout, time.Minute)
+				want(t, "CommonDB.DSN", c.DSN, "postgres://replica")
+				want(t, "limits.RPS", c.RPS, 250)
+				want(t, "Store.Bucket", c.Store.(*example.S3Store).Bucket, "configs")
+				want(t, "Store.Region", c.Store.(*example.S3Store).Region, "eu-west-2")
+			},
+		},
+		{
+			name: "required variable missing",
+			env:  env.Map{"DB_PASSWORD": "secret"},
+			err:  "DB_HOST",
+		},
+		{
+			name: "parse error",
+			env:  env.Map{"DB_HOST": "db", "DB_PASSWORD": "secret", "APP_PORT": "x"},
+			err:  "failed to parse value x for field Port",
+		},
+		{
+			name: "below minimum",
+			env:  env.Map{"DB_HOST": "db", "DB_PASSWORD": "secret", "APP_PORT": "0"},
+			err:  "Port: value 0 is less than 1",
+		},
+		{
+			name: "empty",
+			env:  env.Map{"DB_HOST": "db", "DB_PASSWORD": "secret", "APP_LEVEL": ""},
+			err:  "Level",
+		},
+		{
+			name: "invalid IP",
+			env:  env.Map{"DB_HOST": "db", "DB_PASSWORD": "secret", "APP_BIND": "localhost"},
+			err:  "Bind",
+		},
+	}
+
+	logger := log.New(io.Discard, "", 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				parsed, generated     example.Config
+				parsedRep, genRep     env.Report
+				parsedRatio, genRatio = 1.0, 1.0
+			)
+			// presets exercise noOverwrite and the population of non-nil pointers.
+			parsed.Region, generated.Region = "us-east-1", "us-east-1"
+			parsed.Ratio, generated.Ratio = &parsedRatio, &genRatio
+			if _, ok := tt.env["CACHE_SIZE"]; ok {
+				parsed.Cache, generated.Cache = &example.Cache{}, &example.Cache{}
+			}
+			if _, ok := tt.env["STORE_BUCKET"]; ok {
+				parsed.Store, generated.Store = &example.S3Store{}, &example.S3Store{}
+			}
+
+			parsedErr := env.Parse(&parsed, env.WithLookuper(tt.env), env.WithReport(&parsedRep), env.WithLogger(logger))
+			genErr := generated.LoadFromEnv(tt.env.LookupEnv, env.WithReport(&genRep), env.WithLogger(logger))
+
+			for name, err := range map[string]error{"env.Parse": parsedErr, "LoadFromEnv": genErr} {
+				switch {
+				case tt.err == "" && err != nil:
+					t.Fatalf("%s: unexpected error: %v", name, err)
+				case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+					t.Fatalf("%s: error %v, want one containing %q", name, err, tt.err)
+				}
+			}
+			if tt.err != "" {
+				if parsedErr.Error() != genErr.Error() {
+					t.Errorf("errors differ:\nenv.Parse   %v\nLoadFromEnv %v", parsedErr, genErr)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(parsed, generated) {
+				t.Errorf("values differ:\nenv.Parse   %s\nLoadFromEnv %s", dump(parsed), dump(generated))
+			}
+			parsedRep.LoadedAt, genRep.LoadedAt = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(parsedRep, genRep) {
+				t.Errorf("reports differ:\nenv.Parse   %s\nLoadFromEnv %s", dump(parsedRep), dump(genRep))
+			}
+			tt.check(t, &parsed)
+		})
+	}
+}
+
+func want(t *testing.T, field string, got, want interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
+
+func dump(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
@@ -0,0 +1,558 @@
+// Command envgen generates reflection-free loaders for structs with env tags. For every type it
+// emits a LoadFromEnv method behaving like env.Parse, which only depends on the envload package:
+//
+//	//go:generate go run github.com/tamarakaufler/go-and-reflect/cmd/envgen -type Config
+//
+//	err := cfg.LoadFromEnv(os.LookupEnv)
+//
+// Fields of types env.Parse cannot set are reported when the code is generated rather than
+// when it runs. Interface fields are the exception to the reflection-free loading: the struct they
+// point to is only known at run time, env.LoadInterface populates it with reflection.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+const (
+	suffix = "_envgen.go"
+
+	envPath     = "github.com/tamarakaufler/go-and-reflect/env"
+	envloadPath = "github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("envgen: ")
+
+	typeNames := flag.String("type", "", "comma separated list of struct type names (required)")
+	output := flag.String("output", "", "output file name, <package>"+suffix+" by default")
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	pkg, err := loadPackage(dir, *output)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := newGenerator(pkg)
+	for _, name := range strings.Split(*typeNames, ",") {
+		err = g.generate(strings.TrimSpace(name))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	src, err := g.source()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := *output
+	if out == "" {
+		out = pkg.Name() + suffix
+	}
+	err = os.WriteFile(filepath.Join(dir, out), src, 0o644) //nolint:gosec // generated source is not secret.
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadPackage parses and type checks the package in dir, leaving out tests and generated loaders.
+func loadPackage(dir, output string) (*types.Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		n := fi.Name()
+		return !strings.HasSuffix(n, "_test.go") && !strings.HasSuffix(n, suffix) && n != output
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var files []*ast.File
+	for _, p := range pkgs {
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+	}
+
+	// type errors are expected as long as the package uses the loaders it is generating, only
+	// the declarations of the types matter.
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(dir, fset, files, nil)
+	return pkg, nil
+}
+
+type generator struct {
+	pkg     *types.Package
+	imports map[string]string // path -> name
+	body    bytes.Buffer
+}
+
+func newGenerator(pkg *types.Package) *generator {
+	return &generator{
+		pkg: pkg,
+		imports: map[string]string{
+			envloadPath: "envload",
+		},
+	}
+}
+
+// typeGen generates the loader of one struct type.
+type typeGen struct {
+	*generator
+	typeName string
+	vars     []envload.Var
+	code     bytes.Buffer
+	depth    int
+	visiting map[*types.Named]bool
+}
+
+func (g *generator) generate(name string) error {
+	obj := g.pkg.Scope().Lookup(name)
+	if obj == nil {
+		return fmt.Errorf("type %s not found", name)
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return fmt.Errorf("%s is not a named type", name)
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return fmt.Errorf("%s is not a struct", name)
+	}
+
+	tg := &typeGen{
+		generator: g,
+		typeName:  name,
+		depth:     1,
+		visiting:  map[*types.Named]bool{named: true},
+	}
+	err := tg.fields(st, "c", "", "")
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	tg.write()
+	return nil
+}
+
+// fields generates the code populating the fields of the struct st accessed through expr. prefix
+// is the envPrefix of the enclosing structs.
+func (tg *typeGen) fields(st *types.Struct, expr, path, prefix string) error {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		_, nested := nestedStruct(deref(f.Type()))
+		// env.Parse cannot set unexported fields, but sets the promoted fields of unexported
+		// embedded structs.
+		if !f.Exported() && !(f.Embedded() && nested) {
+			continue
+		}
+		err := tg.field(f, reflect.StructTag(st.Tag(i)), expr+"."+f.Name(), fieldPath(path, f.Name()), prefix)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field generates the code populating the field f accessed through expr, by kind: nested structs
+// field by field, interface fields through the struct they point to and other fields from their
+// variable.
+func (tg *typeGen) field(f *types.Var, tag reflect.StructTag, expr, path, prefix string) error {
+	if st, ok := nestedStruct(deref(f.Type())); ok {
+		if hasEnvTags(tag) {
+			return fmt.Errorf("field %s: env tags on a struct field are not supported", path)
+		}
+		return tg.structField(f, st, tag, expr, path, prefix)
+	}
+	if _, ok := f.Type().Underlying().(*types.Interface); ok && !hasEnvTags(tag) {
+		tg.interfaceField(tag, expr, path, prefix)
+		return nil
+	}
+	return tg.valueField(f, tag, expr, path, prefix)
+}
+
+// structField generates the code populating the nested struct st, the type of f or the type f
+// points to.
+func (tg *typeGen) structField(f *types.Var, st *types.Struct, tag reflect.StructTag, expr, path, prefix string) error {
+	elem := deref(f.Type())
+	if named, ok := elem.(*types.Named); ok {
+		if tg.visiting[named] {
+			return fmt.Errorf("field %s: recursive type %s is not supported", path, named.Obj().Name())
+		}
+		tg.visiting[named] = true
+		defer delete(tg.visiting, named)
+	}
+	prefix += tag.Get("envPrefix")
+
+	if _, isPtr := f.Type().Underlying().(*types.Pointer); !isPtr {
+		return tg.fields(st, expr, path, prefix)
+	}
+
+	// like env.Parse, nil embedded pointers are allocated unless unexported, other nil
+	// pointers to structs are left as they are.
+	if f.Embedded() && f.Exported() {
+		tg.line("if %s == nil {", expr)
+		tg.line("	%s = new(%s)", expr, types.TypeString(elem, tg.qualifier))
+		tg.line("}")
+		return tg.fields(st, expr, path, prefix)
+	}
+	tg.line("if %s != nil {", expr)
+	tg.depth++
+	err := tg.fields(st, expr, path, prefix)
+	if err != nil {
+		return err
+	}
+	tg.depth--
+	if f.Embedded() {
+		tg.line("}")
+		return nil
+	}
+	tg.line("} else {")
+	tg.depth++
+	tg.value(envload.Var{Field: path}, "false")
+	tg.line("_ = fv")
+	tg.depth--
+	tg.line("}")
+	return nil
+}
+
+// interfaceField generates the code populating the struct an interface field points to. Its
+// concrete type is only known at run time, the generated code depends on env for it.
+func (tg *typeGen) interfaceField(tag reflect.StructTag, expr, path, prefix string) {
+	tg.imports[envPath] = "env"
+	tg.vars = append(tg.vars, envload.Var{Field: path})
+	tg.line("err = env.LoadInterface(l, %s, &%s[%d], %q)", expr, tg.varsName(), len(tg.vars)-1,
+		prefix+tag.Get("envPrefix"))
+	tg.line("if err != nil {")
+	tg.line("\treturn err")
+	tg.line("}")
+}
+
+// valueField generates the code resolving the variable of the field f and parsing it into
+// the field.
+func (tg *typeGen) valueField(f *types.Var, tag reflect.StructTag, expr, path, prefix string) error {
+	t := f.Type()
+	_, isPtr := t.Underlying().(*types.Pointer)
+	elem := deref(t)
+
+	typ, ok := tg.schemaType(elem)
+	if !ok {
+		if hasEnvTags(tag) {
+			return fmt.Errorf("field %s: unsupported type %s", path, types.TypeString(t, tg.qualifier))
+		}
+		// nothing provides a value, the field is only reported.
+		tg.value(envload.Var{Field: path}, "false")
+		return nil
+	}
+
+	tg.value(env.TagVar(path, typ, tag).WithPrefix(prefix), tg.preset(t, expr))
+	tg.line("if fv != nil {")
+	tg.depth++
+	target := expr
+	if isPtr {
+		tg.line("if %s == nil {", expr)
+		tg.line("\t%s = new(%s)", expr, types.TypeString(elem, tg.qualifier))
+		tg.line("}")
+		target = "*" + expr
+	}
+	tg.assign(target, elem, typ, f.Name())
+	tg.depth--
+	tg.line("}")
+	return nil
+}
+
+// value generates the call resolving the value of a field into fv.
+func (tg *typeGen) value(v envload.Var, preset string) {
+	tg.vars = append(tg.vars, v)
+	tg.line("fv, err = l.Value(&%s[%d], %s)", tg.varsName(), len(tg.vars)-1, preset)
+	tg.line("if err != nil {")
+	tg.line("\treturn err")
+	tg.line("}")
+}
+
+// assign generates the code parsing fv.Value into target, as env.Parse parsers do.
+func (tg *typeGen) assign(target string, t types.Type, typ, name string) {
+	ts := types.TypeString(t, tg.qualifier)
+	// conv converts v, of type ret, to the type of the field.
+	conv := func(ret string) string {
+		if ts == ret {
+			return "v"
+		}
+		return ts + "(v)"
+	}
+	parse := func(call, conv string) {
+		tg.line("{")
+		tg.line("\tv, err := %s", call)
+		tg.line("\tif err != nil {")
+		tg.line("\t\treturn fv.ParseError(%q)", name)
+		tg.line("\t}")
+		tg.line("\t%s = %s", target, conv)
+		tg.line("}")
+	}
+
+	switch typ {
+	case "string":
+		if ts == "string" {
+			tg.line("%s = fv.Value", target)
+			break
+		}
+		tg.line("%s = %s(fv.Value)", target, ts)
+	case "bool":
+		tg.imports["strconv"] = "strconv"
+		parse("strconv.ParseBool(fv.Value)", conv("bool"))
+	case "int", "int8", "int32", "int64":
+		tg.imports["strconv"] = "strconv"
+		bits := strings.TrimPrefix(typ, "int")
+		if bits == "" {
+			bits = "0"
+		}
+		parse(fmt.Sprintf("strconv.ParseInt(fv.Value, 0, %s)", bits), conv("int64"))
+	case "float32", "float64":
+		tg.imports["strconv"] = "strconv"
+		parse(fmt.Sprintf("strconv.ParseFloat(fv.Value, %s)", strings.TrimPrefix(typ, "float")), conv("float64"))
+	case "duration":
+		tg.imports["time"] = "time"
+		parse("time.ParseDuration(fv.Value)", conv("time.Duration"))
+	case "url":
+		tg.imports["net/url"] = "url"
+		parse("url.Parse(fv.Value)", "*v")
+	case "ip":
+		tg.imports["net"] = "net"
+		tg.line("if ip := net.ParseIP(fv.Value); ip != nil {")
+		tg.line("\t%s = ip", target)
+		tg.line("} else {")
+		tg.line("\treturn fv.ParseError(%q)", name)
+		tg.line("}")
+	}
+}
+
+// schemaType returns the env.Var type name of t if env.Parse can set it.
+func (tg *typeGen) schemaType(t types.Type) (string, bool) {
+	if n, ok := t.(*types.Named); ok && n.Obj().Pkg() != nil {
+		switch n.Obj().Pkg().Path() + "." + n.Obj().Name() {
+		case "time.Duration":
+			return "duration", true
+		case "net/url.URL":
+			return "url", true
+		case "net.IP":
+			return "ip", true
+		}
+	}
+
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return "", false
+	}
+	switch b.Kind() {
+	case types.String, types.Bool, types.Int, types.Int8, types.Int32, types.Int64, types.Float32, types.Float64:
+		return b.Name(), true
+	}
+	return "", false
+}
+
+// preset returns the expression telling whether the field is non-zero, as reflect.Value.IsZero.
+func (tg *typeGen) preset(t types.Type, expr string) string {
+	switch u := t.Underlying().(type) {
+	case *types.Pointer, *types.Slice:
+		return expr + " != nil"
+	case *types.Struct:
+		return fmt.Sprintf("%s != (%s{})", expr, types.TypeString(t, tg.qualifier))
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			return expr + ` != ""`
+		case u.Info()&types.IsBoolean != 0:
+			return expr
+		}
+	}
+	return expr + " != 0"
+}
+
+func (tg *typeGen) qualifier(p *types.Package) string {
+	if p == tg.pkg {
+		return ""
+	}
+	tg.imports[p.Path()] = p.Name()
+	return p.Name()
+}
+
+func (tg *typeGen) varsName() string {
+	return "envgenVars" + tg.typeName
+}
+
+func (tg *typeGen) line(format string, a ...interface{}) {
+	tg.code.WriteString(strings.Repeat("\t", tg.depth))
+	fmt.Fprintf(&tg.code, format, a...)
+	tg.code.WriteByte('\n')
+}
+
+func (tg *typeGen) write() {
+	b := &tg.body
+	fmt.Fprintf(b, "// LoadFromEnv populates c from the variables looked up with lookup, eg os.LookupEnv, following\n")
+	fmt.Fprintf(b, "// the same rules as env.Parse without reflection.\n")
+	fmt.Fprintf(b, "func (c *%s) LoadFromEnv(lookup func(string) (string, bool), opts ...envload.Option) error {\n",
+		tg.typeName)
+	fmt.Fprintf(b, "\tl, err := envload.NewLoader(lookup, opts...)\n")
+	fmt.Fprintf(b, "\tif err != nil {\n\t\treturn err\n\t}\n\n")
+	fmt.Fprintf(b, "\tvar fv *envload.FieldValue\n")
+	b.Write(tg.code.Bytes())
+	fmt.Fprintf(b, "\t_ = fv\n\treturn nil\n}\n\n")
+
+	fmt.Fprintf(b, "var %s = [...]envload.Var{\n", tg.varsName())
+	for _, v := range tg.vars {
+		fmt.Fprintf(b, "\t%s,\n", varLiteral(v))
+	}
+	fmt.Fprintf(b, "}\n\n")
+}
+
+func (g *generator) source() ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by envgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", g.pkg.Name())
+
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	// standard library imports first, as goimports groups them.
+	sort.SliceStable(paths, func(i, j int) bool {
+		return !strings.Contains(paths[i], ".") && strings.Contains(paths[j], ".")
+	})
+	fmt.Fprintf(&b, "import (\n")
+	for i, p := range paths {
+		if i > 0 && strings.Contains(p, ".") && !strings.Contains(paths[i-1], ".") {
+			fmt.Fprintf(&b, "\n")
+		}
+		if g.imports[p] != filepath.Base(p) {
+			fmt.Fprintf(&b, "\t%s %q\n", g.imports[p], p)
+			continue
+		}
+		fmt.Fprintf(&b, "\t%q\n", p)
+	}
+	fmt.Fprintf(&b, ")\n\n")
+
+	b.Write(g.body.Bytes())
+	fmt.Fprintf(&b, "func envgenString(s string) *string {\n\treturn &s\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, errors.New("failed to format generated code: " + err.Error())
+	}
+	return src, nil
+}
+
+// varLiteral returns the Go literal of v.
+func varLiteral(v envload.Var) string {
+	ff := []string{fmt.Sprintf("Field: %q", v.Field)}
+	str := func(name, s string) {
+		if s != "" {
+			ff = append(ff, fmt.Sprintf("%s: %q", name, s))
+		}
+	}
+	list := func(name string, ss []string) {
+		if len(ss) > 0 {
+			ff = append(ff, fmt.Sprintf("%s: %#v", name, ss))
+		}
+	}
+	flag := func(name string, b bool) {
+		if b {
+			ff = append(ff, name+": true")
+		}
+	}
+
+	str("Name", v.Name)
+	str("Type", v.Type)
+	list("Aliases", v.Aliases)
+	list("Deprecated", v.Deprecated)
+	if v.Default != nil {
+		ff = append(ff, fmt.Sprintf("Default: envgenString(%q)", *v.Default))
+	}
+	if len(v.ProfileDefaults) > 0 {
+		ff = append(ff, fmt.Sprintf("ProfileDefaults: %#v", v.ProfileDefaults))
+	}
+	flag("Required", v.Required)
+	flag("NotEmpty", v.NotEmpty)
+	flag("Unset", v.Unset)
+	flag("NoOverwrite", v.NoOverwrite)
+	flag("Secret", v.Secret)
+	str("Description", v.Description)
+	list("Enum", v.Enum)
+	str("Min", v.Min)
+	str("Max", v.Max)
+
+	return "{" + strings.Join(ff, ", ") + "}"
+}
+
+// nestedStruct tells whether t is a struct env.Parse populates field by field.
+func nestedStruct(t types.Type) (*types.Struct, bool) {
+	if n, ok := t.(*types.Named); ok && n.Obj().Pkg() != nil &&
+		n.Obj().Pkg().Path() == "net/url" && n.Obj().Name() == "URL" {
+		return nil, false
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	return st, ok
+}
+
+func deref(t types.Type) types.Type {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return t
+}
+
+// envTagKeys are the tag keys describing the variable of a field, besides the profile specific
+// defaults, eg envDefault.prod.
+var envTagKeys = []string{
+	"env", "envDefault", "envAliases", "envDeprecated", "envDescription", "envEnum", "envMin", "envMax",
+}
+
+// hasEnvTags tells whether tag describes the variable of a field.
+func hasEnvTags(tag reflect.StructTag) bool {
+	for _, k := range envTagKeys {
+		if _, ok := tag.Lookup(k); ok {
+			return true
+		}
+	}
+	return len(env.TagVar("", "", tag).ProfileDefaults) > 0
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+// layout prints the memory layout of the struct types declared in a package, read from its source.
+func layout(args []string) error {
+	fs := flag.NewFlagSet("layout", flag.ExitOnError)
+	typeNames := fs.String("type", "", "comma separated list of struct type names, all struct types by default")
+	arch := fs.String("arch", runtime.GOARCH, "architecture the sizes are computed for, eg 386 or arm64")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: reflect layout [flags] [dir]")
+		fs.PrintDefaults()
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	sizes := types.SizesFor("gc", *arch)
+	if sizes == nil {
+		return fmt.Errorf("unknown architecture %s", *arch)
+	}
+	pkg, err := loadPackage(dir, sizes)
+	if err != nil {
+		return err
+	}
+
+	structs, err := structTypes(pkg, *typeNames)
+	if err != nil {
+		return err
+	}
+	for i, tn := range structs {
+		if i > 0 {
+			fmt.Println()
+		}
+		err = structLayout(pkg, tn, sizes).Print(os.Stdout)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPackage parses and type checks the package in dir, leaving out tests.
+func loadPackage(dir string, sizes types.Sizes) (*types.Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var files []*ast.File
+	for _, p := range pkgs {
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Sizes:    sizes,
+	}
+	return conf.Check(dir, fset, files, nil)
+}
+
+// structTypes returns the struct types of the package named in the comma separated list, or
+// all of them in the order they are declared. Generic types have no layout of their own.
+func structTypes(pkg *types.Package, names string) ([]*types.TypeName, error) {
+	scope := pkg.Scope()
+	if names != "" {
+		var structs []*types.TypeName
+		for _, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !isStruct(tn) {
+				return nil, fmt.Errorf("struct type %s not found", name)
+			}
+			structs = append(structs, tn)
+		}
+		return structs, nil
+	}
+
+	var structs []*types.TypeName
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if ok && isStruct(tn) {
+			structs = append(structs, tn)
+		}
+	}
+	sort.Slice(structs, func(i, j int) bool {
+		return structs[i].Pos() < structs[j].Pos()
+	})
+	return structs, nil
+}
+
+func isStruct(tn *types.TypeName) bool {
+	if n, ok := tn.Type().(*types.Named); ok && n.TypeParams().Len() > 0 {
+		return false
+	}
+	_, ok := tn.Type().Underlying().(*types.Struct)
+	return ok
+}
+
+// structLayout returns the layout of the struct type tn computed with the sizes.
+func structLayout(pkg *types.Package, tn *types.TypeName, sizes types.Sizes) *refl.StructLayout {
+	st := tn.Type().Underlying().(*types.Struct)
+
+	vars := make([]*types.Var, st.NumFields())
+	for i := range vars {
+		vars[i] = st.Field(i)
+	}
+	offsets := sizes.Offsetsof(vars)
+
+	fields := make([]refl.FieldLayout, 0, len(vars))
+	for i, v := range vars {
+		fields = append(fields, refl.FieldLayout{
+			Name:   v.Name(),
+			Type:   types.TypeString(v.Type(), types.RelativeTo(pkg)),
+			Offset: uintptr(offsets[i]),
+			Size:   uintptr(sizes.Sizeof(v.Type())),
+			Align:  uintptr(sizes.Alignof(v.Type())),
+		})
+	}
+	return refl.NewStructLayout(tn.Name(), uintptr(sizes.Sizeof(st)), uintptr(sizes.Alignof(st)), fields)
+}
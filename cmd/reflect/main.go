@@ -1,8 +1,14 @@
+// Command reflect demonstrates the reflect package on the User struct. With the layout subcommand it
+// reports the memory layout of the structs of a package instead:
+//
+//	go run ./cmd/reflect
+//	go run ./cmd/reflect layout [-type User,Address] [-arch amd64] [dir]
 package main
 
 import (
 	"log"
 	"os"
+	"reflect"
 
 	refl "github.com/tamarakaufler/go-and-reflect/reflect"
 )
@@ -12,6 +18,10 @@ type User struct {
 	Age     float32 `env:"USER_AGE" envDefault:"23.5"`
 	Address Address
 
+	Friends []*User
+	Places  map[string]LatLng
+	Extra   interface{}
+
 	nationalInsurance string //nolint:structcheck,unused
 }
 
@@ -28,6 +38,17 @@ type LatLng struct {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "layout" {
+		err := layout(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	demo()
+}
+
+func demo() {
 	os.Setenv("USER_NAME", "Rebecca")
 	os.Setenv("USER_ADDRESS_STREET", "16 St Mary's Close")
 	os.Setenv("USER_ADDRESS_CITY", "St Albans")
@@ -38,7 +59,11 @@ func main() {
 
 	cfg := &User{}
 
-	err := refl.Parse(cfg)
+	s, err := refl.Parse(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = s.Print(os.Stdout)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -48,8 +73,29 @@ func main() {
 		Name:    "Marianne",
 		Age:     33,
 		Address: Address{},
+		Friends: []*User{{Name: "Lucien"}, {}},
+		Places:  map[string]LatLng{"eu": {Lat: 51.75, Lng: -0.33}},
+		Extra:   &Address{City: "Oxford"},
 	}
-	err = refl.Parse(cfg)
+	s, err = refl.Parse(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = s.Print(os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("=====================================================")
+	// a visitor mutating the values: empty strings are set to their envDefault.
+	err = refl.Walk(cfg, refl.VisitorFunc(func(path string, f *refl.FieldInfo, v reflect.Value) (refl.Action, error) {
+		t, ok := f.Tag("envDefault")
+		if ok && v.Kind() == reflect.String && v.CanSet() && v.String() == "" {
+			log.Printf("%s = %q\n", path, t.Value)
+			v.SetString(t.Value)
+		}
+		return refl.Continue, nil
+	}))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,174 @@
+// Command tagcheck reports struct tags that reflection based packages silently ignore or misread,
+// running the tagcheck analyzer over the packages in the given directories:
+//
+//	go run ./cmd/tagcheck ./...
+//
+// dir/... checks the packages in dir and below it. Tests are left out. The exit status is 1 when
+// issues are found.
+//
+// tagcheck also runs as a vet tool, through the unitchecker protocol of golang.org/x/tools, in which
+// case go vet loads the packages, tests included:
+//
+//	go build -o tagcheck ./cmd/tagcheck
+//	go vet -vettool=$(pwd)/tagcheck ./...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tamarakaufler/go-and-reflect/tagcheck"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	if vetTool(os.Args[1:]) {
+		unitchecker.Main(tagcheck.Analyzer)
+	}
+
+	log.SetFlags(0)
+	log.SetPrefix("tagcheck: ")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: tagcheck [dir | dir/...]...\n\n%s\n", tagcheck.Analyzer.Doc)
+	}
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	dirs, err := expand(patterns)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil)
+	var diags []string
+	for _, dir := range dirs {
+		dd, err := check(fset, imp, dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		diags = append(diags, dd...)
+	}
+
+	for _, d := range diags {
+		fmt.Println(d)
+	}
+	if len(diags) > 0 {
+		os.Exit(1)
+	}
+}
+
+// vetTool tells whether tagcheck is run by go vet, which asks for the version with -V=full and
+// the flags with -flags before running it on the .cfg file describing each package.
+func vetTool(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	return strings.HasPrefix(args[0], "-V") || args[0] == "-flags" || strings.HasSuffix(args[len(args)-1], ".cfg")
+}
+
+// expand returns the directories of the patterns, dir/... standing for dir and the directories
+// below it holding Go files. testdata, vendor and hidden directories are left out.
+func expand(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, p := range patterns {
+		if !strings.HasSuffix(p, "...") {
+			dirs = append(dirs, p)
+			continue
+		}
+		root := filepath.Clean(strings.TrimSuffix(p, "..."))
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			name := d.Name()
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			gofiles, err := filepath.Glob(filepath.Join(path, "*.go"))
+			if err != nil {
+				return err
+			}
+			if len(gofiles) > 0 {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// check runs the analyzer over the package in dir and returns its diagnostics as file:line:col: message.
+func check(fset *token.FileSet, imp types.Importer, dir string) ([]string, error) {
+	pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []string
+	for _, p := range pkgs {
+		var files []*ast.File
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+
+		// the declarations of the struct types are what matters, other type errors are left to
+		// the compiler.
+		conf := types.Config{
+			Importer: imp,
+			Error:    func(error) {},
+		}
+		info := &types.Info{
+			Types: map[ast.Expr]types.TypeAndValue{},
+			Defs:  map[*ast.Ident]types.Object{},
+		}
+		pkg, _ := conf.Check(dir, fset, files, info)
+
+		pass := &analysis.Pass{
+			Analyzer:  tagcheck.Analyzer,
+			Fset:      fset,
+			Files:     files,
+			Pkg:       pkg,
+			TypesInfo: info,
+		}
+		var found []analysis.Diagnostic
+		pass.Report = func(d analysis.Diagnostic) {
+			found = append(found, d)
+		}
+		_, err = tagcheck.Analyzer.Run(pass)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].Pos < found[j].Pos
+		})
+		for _, d := range found {
+			diags = append(diags, fmt.Sprintf("%s: %s", fset.Position(d.Pos), d.Message))
+		}
+	}
+	return diags, nil
+}
@@ -0,0 +1,127 @@
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestParseAliases(t *testing.T) {
+	type db struct {
+		Host string `env:"HOST" envAliases:"DATABASE_HOST,PGHOST" envDeprecated:"PGHOST"`
+	}
+	type config struct {
+		DB   db     `envPrefix:"DB_"`
+		Port string `env:"PORT,required" envAliases:"OLD_PORT" envDeprecated:"OLD_PORT"`
+	}
+
+	tests := []struct {
+		name       string
+		vars       env.Map
+		host       string
+		hostVar    string
+		deprecated []string
+		err        string
+	}{
+		{
+			name:    "name",
+			vars:    env.Map{"DB_HOST": "db", "DB_DATABASE_HOST": "alias", "DB_PGHOST": "pg", "PORT": "1"},
+			host:    "db",
+			hostVar: "DB_HOST",
+		},
+		{
+			name:    "first alias",
+			vars:    env.Map{"DB_DATABASE_HOST": "alias", "DB_PGHOST": "pg", "PORT": "1"},
+			host:    "alias",
+			hostVar: "DB_DATABASE_HOST",
+		},
+		{
+			name:       "deprecated alias",
+			vars:       env.Map{"DB_PGHOST": "pg", "PORT": "1"},
+			host:       "pg",
+			hostVar:    "DB_PGHOST",
+			deprecated: []string{"DB.Host"},
+		},
+		{
+			name:    "empty name wins",
+			vars:    env.Map{"DB_HOST": "", "DB_PGHOST": "pg", "PORT": "1"},
+			hostVar: "DB_HOST",
+		},
+		{
+			name:    "unprefixed alias",
+			vars:    env.Map{"PGHOST": "pg", "PORT": "1"},
+			hostVar: "",
+		},
+		{
+			name:       "required by alias",
+			vars:       env.Map{"OLD_PORT": "1"},
+			deprecated: []string{"Port"},
+		},
+		{
+			name: "required lists the aliases",
+			vars: env.Map{},
+			err:  "Port requires environment variable PORT or OLD_PORT to be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			report := &env.Report{}
+			l := &logRecorder{}
+			err := env.Parse(&cfg, env.WithLookuper(tt.vars), env.WithReport(report), env.WithLogger(l))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.DB.Host != tt.host {
+				t.Errorf("DB.Host = %q, want %q", cfg.DB.Host, tt.host)
+			}
+			for _, f := range report.Fields {
+				if f.Field == "DB.Host" && f.Var != tt.hostVar {
+					t.Errorf("DB.Host read from %q, want %q", f.Var, tt.hostVar)
+				}
+			}
+
+			var deprecated []string
+			for _, f := range report.Deprecated() {
+				deprecated = append(deprecated, f.Field)
+			}
+			if strings.Join(deprecated, ",") != strings.Join(tt.deprecated, ",") {
+				t.Errorf("deprecated fields %v, want %v", deprecated, tt.deprecated)
+			}
+
+			warnings := strings.Count(l.b.String(), "WARNING")
+			if warnings != len(tt.deprecated) {
+				t.Errorf("%d warnings logged, want %d:\n%s", warnings, len(tt.deprecated), l.b.String())
+			}
+		})
+	}
+}
+
+func TestParseDeprecatedWarning(t *testing.T) {
+	type config struct {
+		Host string `env:"DB_HOST,secret" envAliases:"PGHOST" envDeprecated:"PGHOST"`
+	}
+
+	var cfg config
+	l := &logRecorder{}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"PGHOST": "s3cret"}), env.WithLogger(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "WARNING: Host is set from deprecated environment variable PGHOST, use DB_HOST instead\n"
+	if !strings.Contains(l.b.String(), want) {
+		t.Errorf("log\n%s\nwant it to contain\n%s", l.b.String(), want)
+	}
+	if strings.Contains(l.b.String(), "s3cret") {
+		t.Errorf("secret logged:\n%s", l.b.String())
+	}
+}
@@ -0,0 +1,63 @@
+package env_test
+
+import (
+	"io"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+type (
+	benchUser struct {
+		Name    string  `env:"USER_NAME" envDefault:"Lucien"`
+		Age     float32 `env:"USER_AGE" envDefault:"23.5"`
+		Address benchAddress
+	}
+
+	benchAddress struct {
+		Street   string  `env:"USER_ADDRESS_STREET,required"`
+		City     string  `env:"USER_ADDRESS_CITY,required"`
+		Postcode string  `env:"USER_ADDRESS_POSTCODE,required" envAliases:"USER_ADDRESS_ZIP"`
+		Lat      float64 `env:"USER_ADDRESS_LAT" envDefault:"40.0000"`
+		Lng      float64 `env:"USER_ADDRESS_LNG" envDefault:"-115.1111"`
+	}
+)
+
+var benchVars = env.Map{
+	"USER_NAME":             "Rebecca",
+	"USER_ADDRESS_STREET":   "16 St Mary's Close",
+	"USER_ADDRESS_CITY":     "St Albans",
+	"USER_ADDRESS_POSTCODE": "AL3",
+	"USER_AGE":              "45",
+}
+
+// BenchmarkParse measures Parse populating a struct from a map, as request scoped loaders do,
+// with the plans of the struct types cached and compiled for every Parse.
+func BenchmarkParse(b *testing.B) {
+	logger := log.New(io.Discard, "", 0)
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var u benchUser
+			err := env.Parse(&u, env.WithLookuper(benchVars), env.WithLogger(logger))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			env.ForgetPlans(reflect.TypeOf(benchUser{}), reflect.TypeOf(benchAddress{}))
+			var u benchUser
+			err := env.Parse(&u, env.WithLookuper(benchVars), env.WithLogger(logger))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
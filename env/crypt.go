@@ -0,0 +1,37 @@
+package env
+
+import (
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+const (
+	// EncryptedPrefix marks an environment value holding AES-GCM ciphertext produced by Encrypt.
+	EncryptedPrefix = envload.EncryptedPrefix
+	// DefaultKeyVar is the environment variable holding the decryption key unless WithKeyFile
+	// or WithKeyVar is used.
+	DefaultKeyVar = envload.DefaultKeyVar
+)
+
+// ErrNoKey is returned when an encrypted value is found but no decryption key is available.
+var ErrNoKey = envload.ErrNoKey
+
+// GenerateKey returns a new random 256 bit key, base64 encoded as expected by ParseKey.
+func GenerateKey() (string, error) {
+	return envload.GenerateKey()
+}
+
+// ParseKey decodes a base64 encoded AES key of 16, 24 or 32 bytes.
+func ParseKey(s string) ([]byte, error) {
+	return envload.ParseKey(s)
+}
+
+// Encrypt seals plaintext with AES-GCM and returns it as an enc: prefixed value that Parse
+// decrypts.
+func Encrypt(key []byte, plaintext string) (string, error) {
+	return envload.Encrypt(key, plaintext)
+}
+
+// Decrypt opens an enc: prefixed value produced by Encrypt.
+func Decrypt(key []byte, val string) (string, error) {
+	return envload.Decrypt(key, val)
+}
@@ -0,0 +1,169 @@
+package env
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Debug is an http.Handler serving the effective configuration, for inspecting what a running
+	// process loaded. Secret values are masked.
+	Debug struct {
+		mu     sync.RWMutex
+		cfg    interface{}
+		report *Report
+	}
+
+	// DebugField is the effective value of a configuration field and its provenance.
+	DebugField struct {
+		FieldReport
+		// Value is the value of the field, masked when secret.
+		Value string `json:"value"`
+	}
+
+	// DebugConfig is the effective configuration served by Debug.
+	DebugConfig struct {
+		Fields   []DebugField `json:"fields"`
+		LoadedAt time.Time    `json:"loadedAt"`
+	}
+)
+
+// DebugHandler returns a handler serving the configuration cfg points to, with the provenance
+// recorded in report by Parse (see WithReport). The configuration is served as JSON when
+// the request asks for it, with ?format=json or an Accept header, and as an HTML table otherwise.
+//
+//	report := &env.Report{}
+//	err := env.Parse(&cfg, env.WithReport(report))
+//	...
+//	http.Handle("/debug/config", env.DebugHandler(&cfg, report))
+//
+// The handler must only be exposed locally, eg on the pprof port, even though secrets are masked.
+func DebugHandler(cfg interface{}, report *Report) *Debug {
+	return &Debug{cfg: cfg, report: report}
+}
+
+// Update replaces the configuration served, eg once it is reloaded. The time of the reload is
+// the LoadedAt of the new report.
+func (d *Debug) Update(cfg interface{}, report *Report) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.cfg, d.report = cfg, report
+}
+
+// Publish exposes the configuration as the expvar variable name, served by /debug/vars. expvar
+// variables cannot be removed: publishing under a name already taken, eg by a previous Debug,
+// returns an error instead of panicking as expvar.Publish does. Use Update to serve a reloaded
+// configuration.
+func (d *Debug) Publish(name string) error {
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar %s is already published", name)
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return d.Config()
+	}))
+	return nil
+}
+
+// Config returns the effective configuration.
+func (d *Debug) Config() DebugConfig {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	dc := DebugConfig{Fields: []DebugField{}}
+	if d.report == nil {
+		return dc
+	}
+
+	dc.LoadedAt = d.report.LoadedAt
+	v := reflect.ValueOf(d.cfg)
+	for _, fr := range d.report.Fields {
+		dc.Fields = append(dc.Fields, DebugField{
+			FieldReport: fr,
+			Value:       mask(fieldValue(v, fr.Field), fr.Secret),
+		})
+	}
+	return dc
+}
+
+// ServeHTTP implements http.Handler.
+func (d *Debug) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	dc := d.Config()
+
+	asJSON := r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+	if asJSON {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(dc)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = debugTemplate.Execute(w, dc)
+}
+
+// fieldValue formats the value of the field at the dotted path from the struct v points to.
+// Pointers and interfaces are followed, as Parse does.
+func fieldValue(v reflect.Value, path string) string {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return "<nil>"
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return ""
+		}
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			return ""
+		}
+	}
+
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.CanInterface() {
+		return ""
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Configuration</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.deprecated { color: #b00; }
+</style>
+</head>
+<body>
+<h1>Configuration</h1>
+{{if not .LoadedAt.IsZero}}<p>Loaded at {{.LoadedAt.Format "2006-01-02 15:04:05 MST"}}</p>{{end}}
+<table>
+<tr><th>Field</th><th>Value</th><th>Source</th><th>Variable</th><th>Profile</th><th>Notes</th></tr>
+{{range .Fields}}<tr>
+<td>{{.Field}}</td>
+<td><code>{{.Value}}</code></td>
+<td>{{.Source}}</td>
+<td{{if .Deprecated}} class="deprecated"{{end}}>{{.Var}}</td>
+<td>{{.Profile}}</td>
+<td>{{if .Deprecated}}deprecated {{end}}{{if .Secret}}secret {{end -}}
+{{if .Encrypted}}encrypted {{end}}{{.SecretRef}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,164 @@
+package env_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+type debugConfig struct {
+	Host     string `env:"DB_HOST"`
+	Password string `env:"DB_PASSWORD,secret"`
+	Port     int    `env:"DB_PORT" envDefault:"5432"`
+	Mode     string `env:"MODE"`
+	Internal string
+}
+
+func debugHandler(t *testing.T, vars env.Map) (*env.Debug, *debugConfig) {
+	t.Helper()
+	var cfg debugConfig
+	report := &env.Report{}
+	err := env.Parse(&cfg, env.WithLookuper(vars), env.WithReport(report), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env.DebugHandler(&cfg, report), &cfg
+}
+
+func TestDebugConfig(t *testing.T) {
+	d, _ := debugHandler(t, env.Map{"DB_HOST": "db", "DB_PASSWORD": "s3cret"})
+
+	tests := []struct {
+		field  string
+		value  string
+		source env.Source
+	}{
+		{field: "Host", value: "db", source: env.SourceEnv},
+		{field: "Password", value: "******", source: env.SourceEnv},
+		{field: "Port", value: "5432", source: env.SourceDefault},
+		{field: "Mode", value: "", source: env.SourceNone},
+	}
+
+	fields := d.Config().Fields
+	if len(fields) != len(tests) {
+		t.Fatalf("fields %+v, want %d fields, the untagged Internal left out", fields, len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			f := fields[i]
+			if f.Field != tt.field || f.Value != tt.value || f.Source != tt.source {
+				t.Errorf("field %s = %q from %s, want %s = %q from %s",
+					f.Field, f.Value, f.Source, tt.field, tt.value, tt.source)
+			}
+		})
+	}
+}
+
+func TestDebugServeHTTP(t *testing.T) {
+	d, _ := debugHandler(t, env.Map{"DB_HOST": "<db>", "DB_PASSWORD": "s3cret"})
+
+	tests := []struct {
+		name        string
+		url, accept string
+		contentType string
+		contains    []string
+	}{
+		{
+			name:        "html",
+			url:         "/debug/config",
+			contentType: "text/html; charset=utf-8",
+			contains:    []string{"<table>", "<td>Host</td>", "<code>&lt;db&gt;</code>", "secret"},
+		},
+		{
+			name:        "json query",
+			url:         "/debug/config?format=json",
+			contentType: "application/json",
+			contains:    []string{`"field": "Host"`, `"value": "\u003cdb\u003e"`},
+		},
+		{
+			name:        "json accept",
+			url:         "/debug/config",
+			accept:      "application/json",
+			contentType: "application/json",
+			contains:    []string{`"field": "Password"`, `"value": "******"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			d.ServeHTTP(w, r)
+
+			if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("Content-Type %q, want %q", ct, tt.contentType)
+			}
+			body := w.Body.String()
+			for _, c := range tt.contains {
+				if !strings.Contains(body, c) {
+					t.Errorf("body does not contain %s:\n%s", c, body)
+				}
+			}
+			if strings.Contains(body, "s3cret") {
+				t.Errorf("body holds the secret:\n%s", body)
+			}
+			if strings.Contains(body, "Internal") {
+				t.Errorf("body lists the untagged field:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestDebugUpdate(t *testing.T) {
+	d, _ := debugHandler(t, env.Map{"DB_HOST": "old"})
+
+	var cfg debugConfig
+	report := &env.Report{}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"DB_HOST": "new"}), env.WithReport(report), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Update(&cfg, report)
+
+	dc := d.Config()
+	if dc.Fields[0].Value != "new" {
+		t.Errorf("Host is %q after Update, want new", dc.Fields[0].Value)
+	}
+	if !dc.LoadedAt.Equal(report.LoadedAt) {
+		t.Errorf("LoadedAt %s, want the time of the new report %s", dc.LoadedAt, report.LoadedAt)
+	}
+
+	d.Update(&cfg, nil)
+	if dc := d.Config(); len(dc.Fields) != 0 {
+		t.Errorf("fields %+v without a report, want none", dc.Fields)
+	}
+}
+
+func TestDebugPublish(t *testing.T) {
+	d, _ := debugHandler(t, env.Map{"DB_HOST": "db"})
+
+	err := d.Publish("envDebugPublish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dc env.DebugConfig
+	err = json.Unmarshal([]byte(expvar.Get("envDebugPublish").String()), &dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dc.Fields) == 0 || dc.Fields[0].Value != "db" {
+		t.Errorf("published fields %+v, want Host = db first", dc.Fields)
+	}
+
+	err = d.Publish("envDebugPublish")
+	if err == nil {
+		t.Errorf("publishing the same name again succeeded, want an error")
+	}
+}
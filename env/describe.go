@@ -0,0 +1,177 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+type (
+	// Var describes an environment variable populating a struct field, see envload.Var.
+	Var = envload.Var
+
+	// Schema describes the environment variables of a configuration struct. It is JSON encodable
+	// so that it can be checked against an environment without the Go struct, see cmd/env.
+	Schema struct {
+		Vars []Var `json:"vars"`
+	}
+
+	// Value is the effective value of a schema variable in an environment.
+	Value struct {
+		Var Var
+		// Value is the raw value, neither secret references nor encrypted values are resolved.
+		Value  string
+		Report FieldReport
+		// Err tells why the value is invalid.
+		Err error
+	}
+
+	// ValidationError lists everything wrong with an environment checked against a Schema.
+	ValidationError struct {
+		Errs []error
+	}
+)
+
+// Describe returns the schema of the environment variables populating the struct v points to.
+// It walks the struct type the same way Parse walks its value, including nested structs that
+// are nil pointers. Interface fields are described through the struct pointer they hold in v.
+func Describe(v interface{}) (*Schema, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("input %+v must be a pointer to a struct", v)
+	}
+
+	s := &Schema{}
+	describe(rv.Type().Elem(), rv.Elem(), "", "", map[reflect.Type]bool{}, s)
+	return s, nil
+}
+
+// describe adds the variables of the struct type t to s. v is the value of the struct, it is
+// invalid when the struct is only known by its type, eg behind a nil pointer. stack holds the
+// struct types being described, a recursive type is described once.
+func describe(t reflect.Type, v reflect.Value, path, prefix string, stack map[reflect.Type]bool, s *Schema) {
+	if stack[t] {
+		return
+	}
+	stack[t] = true
+	defer delete(stack, t)
+
+	p := planFor(t)
+	for i := range p.fields {
+		fp := &p.fields[i]
+		fPath := fieldPath(path, fp.name)
+
+		var f reflect.Value
+		if v.IsValid() {
+			f = v.Field(fp.index)
+		}
+
+		if fp.nested {
+			if fp.ptr && f.IsValid() {
+				f = f.Elem() // invalid for nil pointers.
+			}
+			describe(fp.elem, f, fPath, prefix+fp.prefix, stack, s)
+			continue
+		}
+		if fp.iface && f.IsValid() {
+			if e, ok := concreteStruct(f, fp); ok {
+				describe(e.Type(), e, fPath, prefix+fp.prefix, stack, s)
+				continue
+			}
+		}
+
+		if fp.v.Name == "" {
+			continue
+		}
+		s.Vars = append(s.Vars, toVar(fp.v.WithPrefix(prefix), fPath, fp.typ))
+	}
+}
+
+// toVar returns the processed tags v of a field as the Var of the field. Slices and maps are copied,
+// the processed tags are shared by the cached plan of the struct type and changing the Var must
+// not change later Parses.
+func toVar(v Var, field, typ string) Var {
+	v.Field, v.Type = field, typ
+	v.Aliases = copyStrings(v.Aliases)
+	v.Deprecated = copyStrings(v.Deprecated)
+	v.ProfileDefaults = copyMap(v.ProfileDefaults)
+	v.Enum = copyStrings(v.Enum)
+	if v.Default != nil {
+		d := *v.Default
+		v.Default = &d
+	}
+	return v
+}
+
+func copyStrings(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(ss)), ss...)
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// ReadSchema decodes a JSON encoded schema.
+func ReadSchema(r io.Reader) (*Schema, error) {
+	s := &Schema{}
+	err := json.NewDecoder(r).Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return s, nil
+}
+
+// Resolve returns the effective value of every schema variable in the environment envVars, following
+// the same rules as Parse. The profile is read from APP_PROFILE when profile is empty.
+func (s *Schema) Resolve(envVars map[string]string, profile string) []Value {
+	if profile == "" {
+		profile = envVars[DefaultProfileVar]
+	}
+
+	vv := make([]Value, 0, len(s.Vars))
+	for _, v := range s.Vars {
+		val, fr, err := v.Resolve(Map(envVars), profile, false)
+		if err == nil && fr.Source != SourceNone {
+			err = v.Check(val)
+		}
+		vv = append(vv, Value{Var: v, Value: val, Report: fr, Err: err})
+	}
+	return vv
+}
+
+// Check validates the environment envVars against the schema. The returned error is
+// a *ValidationError listing every invalid variable.
+func (s *Schema) Check(envVars map[string]string, profile string) error {
+	ve := &ValidationError{}
+	for _, v := range s.Resolve(envVars, profile) {
+		if v.Err != nil {
+			ve.Errs = append(ve.Errs, v.Err)
+		}
+	}
+	if len(ve.Errs) > 0 {
+		return ve
+	}
+	return nil
+}
+
+func (e *ValidationError) Error() string {
+	ss := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		ss = append(ss, err.Error())
+	}
+	return strings.Join(ss, "; ")
+}
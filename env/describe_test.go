@@ -0,0 +1,69 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestDescribeDoesNotShareThePlan(t *testing.T) {
+	type config struct {
+		Level string `env:"LEVEL" envAliases:"LOG_LEVEL" envEnum:"debug,info" envDefault:"info" envDefault.prod:"debug"`
+	}
+
+	s, err := env.Describe(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &s.Vars[0]
+	v.Aliases[0] = "CHANGED"
+	v.Enum[0] = "trace"
+	v.ProfileDefaults["prod"] = "trace"
+
+	var cfg config
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"LOG_LEVEL": "debug"}), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "debug" {
+		t.Errorf("Level = %q, want debug", cfg.Level)
+	}
+
+	cfg = config{}
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{}), env.WithProfile("prod"), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "debug" {
+		t.Errorf("prod Level = %q, want debug", cfg.Level)
+	}
+}
+
+func TestSchemaCheckSecretReferences(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	env.RegisterSecretProvider("checksecret", env.FileProvider{Dir: t.TempDir()})
+	defer env.RegisterSecretProvider("checksecret", nil)
+
+	s, err := env.Describe(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value string
+		ok    bool
+	}{
+		{value: "8080", ok: true},
+		{value: "checksecret://app/port", ok: true},
+		{value: "enc:c2VhbGVk", ok: true},
+		{value: "http://example.com", ok: false},
+		{value: "unregistered://app/port", ok: false},
+	}
+	for _, tt := range tests {
+		err := s.Check(map[string]string{"PORT": tt.value}, "")
+		if (err == nil) != tt.ok {
+			t.Errorf("Check(PORT=%s) = %v, want ok %t", tt.value, err, tt.ok)
+		}
+	}
+}
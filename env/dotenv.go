@@ -0,0 +1,128 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReadDotenv reads environment variables in the dotenv format:
+//
+//	# comment
+//	export DB_HOST=localhost
+//	DB_NAME="orders" # double quoted values support escapes such as \n
+//	DB_PASSWORD='p#ss' # single quoted values are taken literally
+func ReadDotenv(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		p := strings.SplitN(line, "=", 2)
+		if len(p) != 2 || strings.TrimSpace(p[0]) == "" {
+			return nil, fmt.Errorf("line %d: expected NAME=VALUE", n)
+		}
+
+		v, err := dotenvValue(p[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		vars[strings.TrimSpace(p[0])] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// LoadDotenv reads the dotenv files in order, later files overriding earlier ones.
+func LoadDotenv(paths ...string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		m, err := ReadDotenv(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for k, v := range m {
+			vars[k] = v
+		}
+	}
+	return vars, nil
+}
+
+// WriteDotenv writes vars in the dotenv format, sorted by name.
+func WriteDotenv(w io.Writer, vars map[string]string) error {
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		_, err := fmt.Fprintf(w, "%s=%s\n", k, quoteDotenv(vars[k]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dotenvValue returns the value in raw, the text following the equals sign.
+func dotenvValue(raw string) (string, error) {
+	v := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(v, `"`):
+		end := closingQuote(v)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", v)
+		}
+		return strconv.Unquote(v[:end+1])
+	case strings.HasPrefix(v, "'"):
+		end := strings.Index(v[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", v)
+		}
+		return v[1 : end+1], nil
+	default:
+		// an unquoted value ends at a comment preceded by a space, eg NAME= # comment is empty.
+		v = raw
+		if i := strings.Index(v, " #"); i >= 0 {
+			v = v[:i]
+		}
+		return strings.TrimSpace(v), nil
+	}
+}
+
+// closingQuote returns the index of the double quote closing the value starting with a double quote.
+func closingQuote(v string) int {
+	for i := 1; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func quoteDotenv(v string) string {
+	if strings.ContainsAny(v, " \t\n\r\"'#\\") {
+		return strconv.Quote(v)
+	}
+	return v
+}
@@ -0,0 +1,151 @@
+package env_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestReadDotenv(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		vars  map[string]string
+		err   string
+	}{
+		{
+			name:  "plain",
+			input: "DB_HOST=localhost\n\n  DB_PORT = 5432  \n",
+			vars:  map[string]string{"DB_HOST": "localhost", "DB_PORT": "5432"},
+		},
+		{
+			name:  "comments",
+			input: "# comment\n  # indented comment\nA=a # inline comment\nB=b#not a comment\nC= # empty\n",
+			vars:  map[string]string{"A": "a", "B": "b#not a comment", "C": ""},
+		},
+		{
+			name:  "export",
+			input: "export A=a\n  export B=\"b c\"\nexported=x\n",
+			vars:  map[string]string{"A": "a", "B": "b c", "exported": "x"},
+		},
+		{
+			name:  "double quoted",
+			input: `A="a # b" # comment` + "\n" + `B="line\nbreak \"quoted\" \\ tab\t"` + "\n" + `C=""` + "\n",
+			vars:  map[string]string{"A": "a # b", "B": "line\nbreak \"quoted\" \\ tab\t", "C": ""},
+		},
+		{
+			name:  "single quoted",
+			input: `A='p#ss' # comment` + "\n" + `B='\n "x"'` + "\n",
+			vars:  map[string]string{"A": "p#ss", "B": `\n "x"`},
+		},
+		{
+			name:  "equals in value",
+			input: "DSN=postgres://u@h/db?sslmode=disable\n",
+			vars:  map[string]string{"DSN": "postgres://u@h/db?sslmode=disable"},
+		},
+		{
+			name:  "later wins",
+			input: "A=1\nA=2\n",
+			vars:  map[string]string{"A": "2"},
+		},
+		{name: "no equals", input: "A=a\nB\n", err: "line 2: expected NAME=VALUE"},
+		{name: "no name", input: "=a\n", err: "line 1: expected NAME=VALUE"},
+		{name: "unterminated double quote", input: `A="a` + "\n", err: "line 1: unterminated quoted value"},
+		{name: "unterminated single quote", input: "A='a\n", err: "line 1: unterminated quoted value"},
+		{name: "escaped closing quote", input: `A="a\"` + "\n", err: "line 1: unterminated quoted value"},
+		{name: "invalid escape", input: `A="\q"` + "\n", err: "line 1: invalid syntax"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars, err := env.ReadDotenv(strings.NewReader(tt.input))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ReadDotenv() error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(vars, tt.vars) {
+				t.Errorf("ReadDotenv() = %q, want %q", vars, tt.vars)
+			}
+		})
+	}
+}
+
+func TestWriteDotenv(t *testing.T) {
+	vars := map[string]string{
+		"PLAIN":     "localhost",
+		"EMPTY":     "",
+		"SPACES":    "  padded value  ",
+		"HASH":      "p#ss",
+		"QUOTES":    `say "hi" it's`,
+		"BACKSLASH": `C:\dir`,
+		"NEWLINES":  "line1\nline2\r\n",
+		"TAB":       "a\tb",
+		"UNICODE":   "héslo 🔑",
+		"DSN":       "postgres://u@h/db?sslmode=disable",
+	}
+
+	var b bytes.Buffer
+	err := env.WriteDotenv(&b, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `BACKSLASH="C:\\dir"
+DSN=postgres://u@h/db?sslmode=disable
+EMPTY=
+HASH="p#ss"
+NEWLINES="line1\nline2\r\n"
+PLAIN=localhost
+QUOTES="say \"hi\" it's"
+SPACES="  padded value  "
+TAB="a\tb"
+UNICODE="héslo 🔑"
+`
+	if b.String() != want {
+		t.Errorf("WriteDotenv() wrote\n%s\nwant\n%s", b.String(), want)
+	}
+
+	got, err := env.ReadDotenv(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, vars) {
+		t.Errorf("ReadDotenv(WriteDotenv()) = %q, want %q", got, vars)
+	}
+}
+
+func TestLoadDotenv(t *testing.T) {
+	dir := t.TempDir()
+	base, local := filepath.Join(dir, ".env"), filepath.Join(dir, ".env.local")
+	for path, content := range map[string]string{
+		base:  "A=base\nB=base\n",
+		local: "B=local\nC=local\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vars, err := env.LoadDotenv(base, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"A": "base", "B": "local", "C": "local"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("LoadDotenv() = %q, want %q", vars, want)
+	}
+
+	_, err = env.LoadDotenv(base, filepath.Join(dir, "missing"))
+	if !os.IsNotExist(err) {
+		t.Errorf("LoadDotenv() with a missing file: error %v, want it not to exist", err)
+	}
+}
@@ -0,0 +1,133 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+type (
+	Common struct {
+		Addr string `env:"ADDR" envDefault:":8080"`
+	}
+
+	DBConf struct {
+		DSN string `env:"DSN"`
+	}
+
+	limitsConf struct {
+		RPS int `env:"RPS" envDefault:"100"`
+	}
+
+	Store interface {
+		Kind() string
+	}
+
+	S3 struct {
+		Bucket string `env:"BUCKET,required"`
+	}
+
+	Outer struct {
+		Inner Inner `envPrefix:"INNER_"`
+	}
+
+	Inner struct {
+		Leaf Leaf `envPrefix:"LEAF_"`
+	}
+
+	Leaf struct {
+		Name string `env:"NAME"`
+	}
+)
+
+func (*S3) Kind() string { return "s3" }
+
+func TestParseEmbeddedStructs(t *testing.T) {
+	type config struct {
+		Common `envPrefix:"PUBLIC_"`
+		*DBConf
+		*limitsConf
+	}
+
+	var cfg config
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"PUBLIC_ADDR": ":443", "DSN": "postgres://db", "RPS": "5"}), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// promoted fields are populated, with the envPrefix of the embedded struct.
+	if cfg.Addr != ":443" {
+		t.Errorf("Addr = %q, want :443", cfg.Addr)
+	}
+	// a nil embedded pointer is allocated so that its promoted fields are populated.
+	if cfg.DBConf == nil {
+		t.Fatal("DBConf was not allocated")
+	}
+	if cfg.DSN != "postgres://db" {
+		t.Errorf("DSN = %q, want postgres://db", cfg.DSN)
+	}
+	// an unexported embedded pointer cannot be set through reflection, it stays nil.
+	if cfg.limitsConf != nil {
+		t.Errorf("limitsConf = %+v, want nil", cfg.limitsConf)
+	}
+
+	// a non-nil unexported embedded pointer is populated.
+	cfg = config{limitsConf: &limitsConf{}}
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{"RPS": "5"}), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RPS != 5 {
+		t.Errorf("RPS = %d, want 5", cfg.RPS)
+	}
+}
+
+func TestParseStackedPrefixes(t *testing.T) {
+	type config struct {
+		Outer Outer `envPrefix:"APP_"`
+	}
+
+	var cfg config
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"APP_INNER_LEAF_NAME": "leaf", "NAME": "wrong"}), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Outer.Inner.Leaf.Name != "leaf" {
+		t.Errorf("Outer.Inner.Leaf.Name = %q, want leaf", cfg.Outer.Inner.Leaf.Name)
+	}
+
+	s, err := env.Describe(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Vars) != 1 || s.Vars[0].Name != "APP_INNER_LEAF_NAME" {
+		t.Errorf("Describe vars = %+v, want APP_INNER_LEAF_NAME", s.Vars)
+	}
+}
+
+func TestParseInterfaceFields(t *testing.T) {
+	type config struct {
+		Store Store `envPrefix:"STORE_"`
+		Nil   Store
+		Other interface{}
+	}
+
+	cfg := config{Store: &S3{}, Other: "kept"}
+	err := env.Parse(&cfg, env.WithLookuper(env.Map{"STORE_BUCKET": "configs"}), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := cfg.Store.(*S3).Bucket; b != "configs" {
+		t.Errorf("Store.Bucket = %q, want configs", b)
+	}
+	if cfg.Nil != nil || cfg.Other != "kept" {
+		t.Errorf("Nil = %v, Other = %v, want them left alone", cfg.Nil, cfg.Other)
+	}
+
+	// the struct the interface points to is validated as any other.
+	cfg = config{Store: &S3{}}
+	err = env.Parse(&cfg, env.WithLookuper(env.Map{}), env.WithLogger(nil))
+	if err == nil {
+		t.Error("missing required STORE_BUCKET was not reported")
+	}
+}
@@ -2,88 +2,64 @@ package env
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"net"
+	"net/url"
 	"reflect"
-	"strconv"
 	"strings"
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
 )
 
 type (
-	parseFunc func(string) (interface{}, error)
-	tagInfo   struct {
-		envName    string
-		required   bool
-		envDefault string
+	// parser walks the structs given to Parse, resolving the value of each field with l.
+	parser struct {
+		l *envload.Loader
+		// parsing holds the structs being parsed, a pointer to one of them closes a cycle.
+		parsing map[parsed]bool
 	}
-)
 
-var (
-	envVars        = GetEnvVars()
-	defaultParsers = map[reflect.Kind]parseFunc{
-		reflect.String: func(s string) (interface{}, error) {
-			return s, nil
-		},
-		reflect.Bool: func(s string) (interface{}, error) {
-			p, err := strconv.ParseBool(s)
-			if err != nil {
-				return nil, err
-			}
-			return p, nil
-		},
-		reflect.Float32: func(s string) (interface{}, error) {
-			p, err := strconv.ParseFloat(s, 32)
-			if err != nil {
-				return nil, err
-			}
-			return p, nil
-		},
-		reflect.Float64: func(s string) (interface{}, error) {
-			p, err := strconv.ParseFloat(s, 64)
-			if err != nil {
-				return nil, err
-			}
-			return p, nil
-		},
-		reflect.Int: func(s string) (interface{}, error) {
-			p, err := strconv.ParseInt(s, 0, 0)
-			if err != nil {
-				return nil, err
-			}
-			return p, nil
-		},
-		reflect.Int8: func(s string) (interface{}, error) {
-			p, err := strconv.ParseInt(s, 0, 8)
-			if err != nil {
-				return nil, err
-			}
-			return p, nil
-		},
-		reflect.Int32: func(s string) (interface{}, error) {
-			p, err := strconv.ParseInt(s, 0, 32)
-			if err != nil {
-				return nil, err
-			}
-			return p, nil
-		},
-		reflect.Int64: func(s string) (interface{}, error) {
-			p, err := strconv.ParseInt(s, 0, 64)
-			if err != nil {
-				return nil, err
-			}
-			return p, nil
-		},
+	// parsed identifies a struct by its address and type, as a struct and its first field share
+	// their address.
+	parsed struct {
+		ptr uintptr
+		typ reflect.Type
 	}
 )
 
+// typeNames name the types parsed as a whole, rather than after their kind, in a Schema.
+// They are the types envload has parsers for besides the basic kinds, see envload.Parser.
+var typeNames = map[reflect.Type]string{
+	reflect.TypeOf(time.Duration(0)): "duration",
+	reflect.TypeOf(url.URL{}):        "url",
+	reflect.TypeOf(net.IP{}):         "ip",
+}
+
+// typeName returns the name of the type of a field used in a Schema: the name of a type with
+// a type parser, eg duration, or the kind, eg int. Pointers are named after the type they point to.
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if n, ok := typeNames[t]; ok {
+		return n
+	}
+	return t.Kind().String()
+}
+
+// isNested tells whether a field of type t is a nested struct to parse field by field, rather
+// than a value to parse as a whole, such as url.URL.
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	_, ok := typeNames[t]
+	return t.Kind() == reflect.Struct && !ok
+}
+
 // Parse expects the provided data structure and reports on its content. The input must be
 // a pointer to a struct.
-func Parse(c interface{}) error {
-	log.Println("-----------------------------------------------------")
-	defer func() {
-		log.Println("-----------------------------------------------------")
-	}()
-
+func Parse(c interface{}, opts ...Option) error {
 	// creates a new initialised concrete type stored in the provided interface c.
 	v := reflect.ValueOf(c)
 
@@ -98,9 +74,15 @@ func Parse(c interface{}) error {
 		return fmt.Errorf("the dynamic type of the input %+v must be a struct", e)
 	}
 
-	envVars = GetEnvVars()
+	l, err := envload.NewLoader(nil, opts...)
+	if err != nil {
+		return err
+	}
+	l.Tracef("-----------------------------------------------------\n")
+	defer l.Tracef("-----------------------------------------------------\n")
 
-	err := parse(e)
+	p := &parser{l: l}
+	err = p.parse(e, "", "")
 	if err != nil {
 		return err
 	}
@@ -108,49 +90,86 @@ func Parse(c interface{}) error {
 	return nil
 }
 
-// parse accepts a struct value.
-func parse(v reflect.Value) error {
-	log.Printf("PARSE INPUT: v [%+v]\n", v.Type().Name())
-	t := v.Type() // type of the struct, eg Address (=> t.Name() = Address)
-
-	for i := 0; i < t.NumField(); i++ {
-		f := v.Field(i)
-
-		if !f.CanSet() {
-			continue
+// parse accepts an addressable struct value, the path of the struct from the top level input and
+// the prefix of the names of its variables.
+//
+// Nested structs are parsed field by field, non-nil pointers to structs too. Embedded structs are
+// parsed the same way, nil embedded pointers are allocated first so that the promoted fields
+// are populated (unless the embedded type is unexported, as reflection cannot set it). An interface
+// field holding a non-nil pointer to a struct is parsed through that pointer. A pointer to a struct
+// being parsed, which would recurse forever, is skipped.
+func (p *parser) parse(v reflect.Value, path, prefix string) error {
+	p.l.Tracef("PARSE INPUT: v [%+v]\n", v.Type().Name())
+
+	// a struct reached again through a pointer, eg a parent link, is already being parsed.
+	k := parsed{ptr: v.Addr().Pointer(), typ: v.Type()}
+	if p.parsing[k] {
+		p.l.Tracef("\t\t<<< cycle: %s -> <cycle: %s>\n", path, v.Type().Name())
+		return nil
+	}
+	if p.parsing == nil {
+		p.parsing = make(map[parsed]bool)
+	}
+	p.parsing[k] = true
+	defer delete(p.parsing, k)
+	pl := planFor(v.Type()) // fields of the struct type, eg Address, with their processed tags.
+
+	for i := range pl.fields {
+		fp := &pl.fields[i]
+		f := v.Field(fp.index)
+		fPath := fieldPath(path, fp.name)
+
+		// the current value is not logged, it may be a secret set before, eg a decrypted one.
+		p.l.Tracef("\t\t<<< Struct Field - name: [%s], type: [%s]\n", fp.name, fp.typ)
+
+		// struct field is a pointer to a struct.
+		if fp.nested && fp.ptr {
+			if f.IsNil() && fp.embedded {
+				if !f.CanSet() {
+					continue
+				}
+				f.Set(reflect.New(fp.elem))
+			}
+			if !f.IsNil() {
+				err := p.parse(f.Elem(), fPath, prefix+fp.prefix)
+				if err != nil {
+					return err
+				}
+				continue
+			}
 		}
 
-		tf := t.Field(i) // eg tf.Type.Name() == LatLng
-
-		log.Printf("\t\t<<< Type of input Value - t: [%+v]\n", t)
-		log.Printf("\t\t<<< Struct Field - f: [%+v]\n", f)
-		log.Printf("\t\t<<< Struct Type Field - tf: [%+v]\n", tf)
-
-		// struct field is a non-nil pointer.
-		if f.Kind() == reflect.Ptr && !f.IsNil() {
-			err := Parse(f.Interface()) // Parse accepts an interface type, so we get the f value as an interface
+		// struct field itself is a struct.
+		if fp.nested && !fp.ptr && f.CanAddr() { // Addr refers to memory address.
+			err := p.parse(f, fPath, prefix+fp.prefix)
 			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		// struct field itself is a struct.
-		if f.Kind() == reflect.Struct && f.CanAddr() { // Addr refers to memory address.
-			// f.Addr() returns a pointer to the struct f and Interface() returns the value of f as an interface.
-			err := Parse(f.Addr().Interface())
+		if !f.CanSet() {
+			continue
+		}
+
+		// interface field holding a pointer to a struct.
+		if e, ok := concreteStruct(f, fp); ok {
+			err := p.parse(e, fPath, prefix+fp.prefix)
 			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		fieldV, err := getValue(tf, envVars)
+		fieldV, err := p.getValue(f, fp, fPath, prefix)
 		if err != nil {
 			return err
 		}
+		if fieldV == nil {
+			continue
+		}
 
-		err = setValue(f, tf, fieldV)
+		err = p.setValue(f, fp, fieldV)
 		if err != nil {
 			return err
 		}
@@ -159,79 +178,176 @@ func parse(v reflect.Value) error {
 	return nil
 }
 
-func GetEnvVars() map[string]string {
-	envs := os.Environ()
-
-	envM := make(map[string]string, len(envs))
-	for _, e := range envs {
-		p := strings.SplitN(e, "=", 2)
-		envM[p[0]] = p[1]
+// concreteStruct returns the struct an interface field points to, when it holds a non-nil
+// pointer to a struct parsed field by field.
+func concreteStruct(f reflect.Value, fp *fieldPlan) (reflect.Value, bool) {
+	if !fp.iface || f.IsNil() {
+		return reflect.Value{}, false
+	}
+	e := f.Elem()
+	if e.Kind() != reflect.Ptr || e.IsNil() || !isNested(e.Type()) {
+		return reflect.Value{}, false
 	}
-	return envM
+	return e.Elem(), true
+}
+
+// GetEnvVars returns a copy of the process environment.
+func GetEnvVars() map[string]string {
+	return envload.Environ()
 }
 
 // processTag accepts v.Type().Field(i), where v is reflect.Value, value that an interface contains.
-// v.Type().Field(i) contains also struct field tags metadata.
-func processTag(sf reflect.StructField) tagInfo {
-	ti := tagInfo{}
+// v.Type().Field(i) contains also struct field tags metadata. The returned Var has neither Field
+// nor Type set.
+func processTag(sf reflect.StructField) Var {
+	v := Var{}
 
 	t, okE := sf.Tag.Lookup("env")
 	if okE {
 		p := strings.Split(t, ",")
-		ti.envName = p[0]
-		if len(p) == 2 && p[1] == "required" {
-			ti.required = true
-			return ti
+		v.Name = p[0]
+		v.Aliases = splitNames(sf.Tag.Get("envAliases"))
+		v.Deprecated = splitNames(sf.Tag.Get("envDeprecated"))
+		for _, opt := range p[1:] {
+			switch strings.TrimSpace(opt) {
+			case "required":
+				v.Required = true
+			case "notEmpty":
+				v.NotEmpty = true
+			case "unset":
+				v.Unset = true
+			case "noOverwrite":
+				v.NoOverwrite = true
+			case "secret":
+				v.Secret = true
+			}
 		}
 	}
 
+	v.Description = sf.Tag.Get("envDescription")
+	v.Enum = splitNames(sf.Tag.Get("envEnum"))
+	v.Min = sf.Tag.Get("envMin")
+	v.Max = sf.Tag.Get("envMax")
+
 	d, okD := sf.Tag.Lookup("envDefault")
 	if okD {
-		ti.envDefault = d
+		v.Default = &d
 	}
-	return ti
-}
 
-func getValue(sf reflect.StructField, envVars map[string]string) (string, error) {
-	ti := processTag(sf)
-	log.Printf("\t\tTag Info: [%+v]\n", ti)
-
-	fieldVal := ti.envDefault
-	if ti.envName != "" {
-		envVal, ok := envVars[ti.envName]
-		if ok {
-			log.Printf("\t\tEnv Info: [%s]\n", envVal)
-			return envVal, nil
+	for _, k := range tagKeys(sf.Tag) {
+		if !strings.HasPrefix(k, "envDefault.") {
+			continue
 		}
-		if ti.required {
-			return "", fmt.Errorf("%s requires environment variable %s to be set", sf.Name, ti.envName)
+		if v.ProfileDefaults == nil {
+			v.ProfileDefaults = make(map[string]string)
 		}
+		v.ProfileDefaults[strings.TrimPrefix(k, "envDefault.")] = sf.Tag.Get(k)
 	}
-	return fieldVal, nil
+	return v
 }
 
-// setField sets a struct field's value. It accepts the field Value, the field Type and the value
+// getValue resolves the value of a struct field. It returns nil when the field is to be left as it is,
+// either because its preset value is kept or because nothing provides a value.
+func (p *parser) getValue(f reflect.Value, fp *fieldPlan, path, prefix string) (*FieldValue, error) {
+	v := fp.v.WithPrefix(prefix)
+	v.Field, v.Type = path, fp.typ
+	return p.l.Value(&v, !f.IsZero())
+}
+
+// setField sets a struct field's value. It accepts the field Value, the field plan and the value
 // to set the field to.
-func setValue(f reflect.Value, t reflect.StructField, val string) error {
-	tt := t.Type
+func (p *parser) setValue(f reflect.Value, fp *fieldPlan, fv *FieldValue) error {
 	ff := f
-	if tt.Kind() == reflect.Ptr {
-		tt = tt.Elem() // retrieving element type
-		ff = f.Elem()  // returns the value the pointer points to
+	if fp.ptr {
+		if f.IsNil() {
+			f.Set(reflect.New(fp.elem))
+		}
+		ff = f.Elem() // returns the value the pointer points to
 	}
 
-	parseF, ok := defaultParsers[tt.Kind()]
-	if !ok {
-		return fmt.Errorf("no parser found for %s", t.Name)
+	if fp.parse == nil {
+		return fmt.Errorf("no parser found for %s", fp.name)
 	}
 
-	vv, err := parseF(val)
+	vv, err := fp.parse(fv.Value)
 	if err != nil {
-		return fmt.Errorf("failed to parse value %s for field %s", val, t.Name)
+		return fv.ParseError(fp.name)
 	}
 
-	ff.Set(reflect.ValueOf(vv).Convert(tt)) // converts reflect.ValueOf(vv) into type corresponding to ff
-	log.Printf("\t\t>>> SET var: ff - %+v\n\n", ff)
+	ff.Set(reflect.ValueOf(vv).Convert(fp.elem)) // converts reflect.ValueOf(vv) into type corresponding to ff
+	if p.l.Tracing() {
+		p.l.Tracef("\t\t>>> SET var: ff - %+v\n\n", mask(fmt.Sprintf("%+v", ff), fv.Secret))
+	}
 
 	return nil
 }
+
+// mask hides secret values from logs and error messages.
+func mask(val string, secret bool) string {
+	if secret {
+		return "******"
+	}
+	return val
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// splitNames splits a comma separated list of environment variable names.
+func splitNames(s string) []string {
+	var nn []string
+	for _, n := range strings.Split(s, ",") {
+		n = strings.TrimSpace(n)
+		if n != "" {
+			nn = append(nn, n)
+		}
+	}
+	return nn
+}
+
+// tagKeys returns the keys of a struct tag in the order they appear. It follows the conventional
+// key:"value" format understood by reflect.StructTag.Lookup.
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	for tag != "" {
+		// skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// scan to colon. A space, a quote or a control character is a syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		tag = tag[i+1:]
+		keys = append(keys, name)
+	}
+	return keys
+}
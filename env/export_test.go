@@ -0,0 +1,10 @@
+package env
+
+import "reflect"
+
+// ForgetPlans removes the cached plans of the types, which are compiled again on the next Parse.
+func ForgetPlans(types ...reflect.Type) {
+	for _, t := range types {
+		plans.Delete(t)
+	}
+}
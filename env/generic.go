@@ -0,0 +1,49 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+// ParseAs returns a T, which must be a struct, populated by Parse.
+//
+//	cfg, err := env.ParseAs[Config](env.WithProfile("prod"))
+func ParseAs[T any](opts ...Option) (T, error) {
+	var c T
+	err := Parse(&c, opts...)
+	return c, err
+}
+
+// MustParse is like ParseAs but panics when parsing fails, for configurations loaded at startup.
+func MustParse[T any](opts ...Option) T {
+	c, err := ParseAs[T](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Get returns the value of the environment variable name parsed into a T, or def when the variable
+// is not set. T is any type a struct field populated by Parse can have, eg int or time.Duration.
+//
+//	timeout, err := env.Get("HTTP_TIMEOUT", 5*time.Second)
+func Get[T any](name string, def T) (T, error) {
+	val, ok := OSLookuper.LookupEnv(name)
+	if !ok {
+		return def, nil
+	}
+
+	t := reflect.TypeOf(&def).Elem()
+	parseF, ok := envload.Parser(typeName(t))
+	if !ok || t.Kind() == reflect.Ptr {
+		return def, fmt.Errorf("no parser found for %s", t)
+	}
+
+	vv, err := parseF(val)
+	if err != nil {
+		return def, fmt.Errorf("failed to parse value %s for variable %s", val, name)
+	}
+	return reflect.ValueOf(vv).Convert(t).Interface().(T), nil
+}
@@ -0,0 +1,176 @@
+package env_test
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+type genericConfig struct {
+	Host    string        `env:"HOST" envDefault:"localhost"`
+	Port    int           `env:"PORT,required"`
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"5s"`
+}
+
+func TestParseAs(t *testing.T) {
+	tests := []struct {
+		name string
+		vars env.Map
+		want genericConfig
+		err  string
+	}{
+		{
+			name: "parsed",
+			vars: env.Map{"PORT": "8080", "TIMEOUT": "1m"},
+			want: genericConfig{Host: "localhost", Port: 8080, Timeout: time.Minute},
+		},
+		{
+			name: "error",
+			vars: env.Map{},
+			err:  "Port requires environment variable PORT to be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := env.ParseAs[genericConfig](env.WithLookuper(tt.vars), env.WithLogger(nil))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg != tt.want {
+				t.Errorf("ParseAs() = %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+
+	_, err := env.ParseAs[int](env.WithLogger(nil))
+	if err == nil {
+		t.Errorf("ParseAs[int]() succeeded, want an error as int is not a struct")
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	cfg := env.MustParse[genericConfig](env.WithLookuper(env.Map{"PORT": "1"}), env.WithLogger(nil))
+	if cfg.Port != 1 {
+		t.Errorf("MustParse().Port = %d, want 1", cfg.Port)
+	}
+
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok || !strings.Contains(err.Error(), "PORT") {
+			t.Errorf("MustParse() panicked with %v, want the parse error", r)
+		}
+	}()
+	env.MustParse[genericConfig](env.WithLookuper(env.Map{}), env.WithLogger(nil))
+	t.Errorf("MustParse() returned, want a panic")
+}
+
+type level string
+
+func TestGet(t *testing.T) {
+	t.Setenv("ENV_GET_INT", "0x10")
+	t.Setenv("ENV_GET_BOOL", "true")
+	t.Setenv("ENV_GET_DURATION", "1m")
+	t.Setenv("ENV_GET_URL", "https://example.com/path")
+	t.Setenv("ENV_GET_IP", "10.0.0.1")
+	t.Setenv("ENV_GET_LEVEL", "debug")
+	t.Setenv("ENV_GET_EMPTY", "")
+	t.Setenv("ENV_GET_INVALID", "ten")
+
+	check := func(t *testing.T, got, want interface{}, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Get() = %v, want %v", got, want)
+		}
+	}
+	u, _ := url.Parse("https://example.com/path")
+
+	tests := []struct {
+		name string
+		get  func(t *testing.T)
+	}{
+		{name: "int", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_INT", 1)
+			check(t, v, 16, err)
+		}},
+		{name: "int8", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_INT", int8(1))
+			check(t, v, int8(16), err)
+		}},
+		{name: "bool", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_BOOL", false)
+			check(t, v, true, err)
+		}},
+		{name: "duration", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_DURATION", time.Second)
+			check(t, v, time.Minute, err)
+		}},
+		{name: "url", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_URL", url.URL{})
+			check(t, v, *u, err)
+		}},
+		{name: "ip", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_IP", net.IP(nil))
+			check(t, v.String(), "10.0.0.1", err)
+		}},
+		{name: "named type", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_LEVEL", level("info"))
+			check(t, v, level("debug"), err)
+		}},
+		{name: "empty", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_EMPTY", "default")
+			check(t, v, "", err)
+		}},
+		{name: "default", get: func(t *testing.T) {
+			v, err := env.Get("ENV_GET_MISSING", 42)
+			check(t, v, 42, err)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, tt.get)
+	}
+
+	errs := []struct {
+		name string
+		get  func() error
+		err  string
+	}{
+		{name: "invalid", get: func() error {
+			v, err := env.Get("ENV_GET_INVALID", 7)
+			if v != 7 {
+				t.Errorf("Get() = %d with an invalid value, want the default 7", v)
+			}
+			return err
+		}, err: "failed to parse value ten for variable ENV_GET_INVALID"},
+		{name: "pointer", get: func() error {
+			_, err := env.Get("ENV_GET_INT", (*int)(nil))
+			return err
+		}, err: "no parser found for *int"},
+		{name: "unsupported", get: func() error {
+			_, err := env.Get("ENV_GET_INT", []int(nil))
+			return err
+		}, err: "no parser found for []int"},
+	}
+	for _, tt := range errs {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.get()
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect emitted by JSONSchema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a draft 2020-12 JSON Schema describing the environment variables populating
+// the struct v points to, for tools validating env files. WithProfile selects the profile whose
+// defaults are used.
+func JSONSchema(v interface{}, opts ...Option) ([]byte, error) {
+	s, err := Describe(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(s.JSONSchema(envload.Profile(opts...)), "", "  ")
+}
+
+// JSONSchema returns the schema as a draft 2020-12 JSON Schema object. Every variable, aliases
+// included, is a property. Aliases are annotated deprecated when they are deprecated names.
+// A required variable without a default must be set under one of its names.
+func (s *Schema) JSONSchema(profile string) map[string]interface{} {
+	props := map[string]interface{}{}
+	var (
+		required []string
+		anyOf    []interface{}
+	)
+
+	for _, v := range s.Vars {
+		p := jsonSchemaProperty(v, profile)
+		props[v.Name] = p
+
+		for _, a := range v.Aliases {
+			ap := jsonSchemaProperty(v, profile)
+			ap["description"] = fmt.Sprintf("alias of %s", v.Name)
+			if deprecated(v, a) {
+				ap["deprecated"] = true
+			}
+			props[a] = ap
+		}
+
+		if _, _, ok := v.DefaultFor(profile); !v.Required || ok {
+			continue
+		}
+		if len(v.Aliases) == 0 {
+			required = append(required, v.Name)
+			continue
+		}
+		alt := make([]interface{}, 0, len(v.Names()))
+		for _, n := range v.Names() {
+			alt = append(alt, map[string]interface{}{"required": []string{n}})
+		}
+		anyOf = append(anyOf, map[string]interface{}{"anyOf": alt})
+	}
+
+	js := map[string]interface{}{
+		"$schema":    JSONSchemaDraft,
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		js["required"] = required
+	}
+	if len(anyOf) > 0 {
+		js["allOf"] = anyOf
+	}
+	return js
+}
+
+// Patterns of the values Parse accepts, for the variables given as strings.
+const (
+	boolPattern = `^(1|t|T|TRUE|true|True|0|f|F|FALSE|false|False)$`
+	// integers as Go literals, eg 42, -0x2a or 1_000.
+	intPattern = `^[-+]?(0[xX](_?[0-9a-fA-F])+|0[bB](_?[01])+|0[oO](_?[0-7])+|0(_?[0-7])*|[1-9](_?[0-9])*)$`
+	// decimal floats, Inf and NaN, hexadecimal floats are left out.
+	floatPattern = `^([-+]?((` + digits + `(\.(` + digits + `)?)?|\.` + digits + `)([eE][-+]?` + digits + `)?|` +
+		`[iI][nN][fF]([iI][nN][iI][tT][yY])?)|[nN][aA][nN])$`
+	// durations are in the Go format, eg 1m30s, rather than ISO 8601.
+	durationPattern = `^[-+]?([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+	digits = `[0-9](_?[0-9])*`
+)
+
+// jsonSchemaProperty returns the property of a variable. Booleans and numbers are JSON values or
+// strings, as env files hold them, strings matching the pattern of the values Parse accepts. JSON
+// Schema bounds numbers only, the bounds are stated in the description for strings.
+func jsonSchemaProperty(v Var, profile string) map[string]interface{} {
+	p := map[string]interface{}{"type": "string"}
+
+	switch v.Type {
+	case "bool":
+		p["type"] = []string{"boolean", "string"}
+		p["pattern"] = boolPattern
+	case "int", "int8", "int32", "int64":
+		p["type"] = []string{"integer", "string"}
+		p["pattern"] = intPattern
+	case "float32", "float64":
+		p["type"] = []string{"number", "string"}
+		p["pattern"] = floatPattern
+	case "duration":
+		p["pattern"] = durationPattern
+	case "url":
+		p["format"] = "uri"
+	case "ip":
+		p["anyOf"] = []interface{}{
+			map[string]interface{}{"format": "ipv4"},
+			map[string]interface{}{"format": "ipv6"},
+		}
+	}
+
+	if desc := jsonSchemaDescription(v); desc != "" {
+		p["description"] = desc
+	}
+	if d, _, ok := v.DefaultFor(profile); ok && !v.Secret {
+		p["default"] = jsonValue(v, d)
+	}
+	if len(v.Enum) > 0 {
+		// enum values are accepted as JSON values or as strings.
+		ee := make([]interface{}, 0, 2*len(v.Enum))
+		for _, e := range v.Enum {
+			if j := jsonValue(v, e); j != e {
+				ee = append(ee, j)
+			}
+			ee = append(ee, e)
+		}
+		p["enum"] = ee
+	}
+	if v.Min != "" {
+		if m := jsonValue(v, v.Min); m != v.Min {
+			p["minimum"] = m
+		}
+	}
+	if v.Max != "" {
+		if m := jsonValue(v, v.Max); m != v.Max {
+			p["maximum"] = m
+		}
+	}
+	if v.Secret {
+		p["writeOnly"] = true
+	}
+	return p
+}
+
+// deprecated tells whether name is one of the deprecated names of v.
+func deprecated(v Var, name string) bool {
+	for _, n := range v.Deprecated {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonSchemaDescription returns the description of the variable followed by its bounds, which
+// apply to the values given as strings too.
+func jsonSchemaDescription(v Var) string {
+	var bounds string
+	switch {
+	case v.Min != "" && v.Max != "":
+		bounds = fmt.Sprintf("From %s to %s.", v.Min, v.Max)
+	case v.Min != "":
+		bounds = fmt.Sprintf("At least %s.", v.Min)
+	case v.Max != "":
+		bounds = fmt.Sprintf("At most %s.", v.Max)
+	}
+	if v.Description == "" || bounds == "" {
+		return v.Description + bounds
+	}
+	return v.Description + " " + bounds
+}
+
+// jsonValue converts a value from a tag into the JSON type of the variable, falling back to
+// the string when it does not parse.
+func jsonValue(v Var, s string) interface{} {
+	switch v.Type {
+	case "bool":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case "int", "int8", "int32", "int64":
+		if n, err := strconv.ParseInt(s, 0, 64); err == nil {
+			return n
+		}
+	case "float32", "float64":
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package env_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestJSONSchemaProperties(t *testing.T) {
+	type config struct {
+		Debug bool          `env:"DEBUG" envDefault:"true"`
+		Port  int           `env:"PORT" envDefault:"8080" envMin:"1" envMax:"65535"`
+		Level int8          `env:"LEVEL" envEnum:"1,2,3"`
+		Ratio float64       `env:"RATIO"`
+		Wait  time.Duration `env:"WAIT" envMin:"1s"`
+		Name  string        `env:"NAME" envDescription:"Name of the service."`
+	}
+
+	b, err := env.JSONSchema(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var js struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	err = json.Unmarshal(b, &js)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := func(name, key string, value interface{}) {
+		t.Helper()
+		got := js.Properties[name][key]
+		if b, _ := json.Marshal(got); string(b) != mustJSON(t, value) {
+			t.Errorf("%s: %s = %v, want %v", name, key, got, value)
+		}
+	}
+	want("DEBUG", "type", []string{"boolean", "string"})
+	want("DEBUG", "default", true)
+	want("PORT", "type", []string{"integer", "string"})
+	want("PORT", "default", 8080)
+	want("PORT", "minimum", 1)
+	want("PORT", "maximum", 65535)
+	want("PORT", "description", "From 1 to 65535.")
+	want("LEVEL", "enum", []interface{}{1, "1", 2, "2", 3, "3"})
+	want("RATIO", "type", []string{"number", "string"})
+	want("WAIT", "type", "string")
+	want("WAIT", "minimum", nil)
+	want("WAIT", "description", "At least 1s.")
+	want("NAME", "type", "string")
+	want("NAME", "description", "Name of the service.")
+	for name, p := range js.Properties {
+		for key := range p {
+			if strings.HasPrefix(key, "x-") {
+				t.Errorf("%s: non standard keyword %s", name, key)
+			}
+		}
+		if f, ok := p["format"]; ok && f != "uri" {
+			t.Errorf("%s: format %v, want a registered format", name, f)
+		}
+	}
+
+	// the values matching the patterns are the values Parse accepts.
+	tests := []struct {
+		name   string
+		values []string
+	}{
+		{name: "DEBUG", values: []string{"1", "t", "TRUE", "True", "false", "F", "yes", "on", "tRUE", ""}},
+		{name: "PORT", values: []string{"80", "+80", "09", "1__0", "_1", "0x50", "0X1f", "0o17", "017", "0b101", "1_000", "1.5", "x", "0x", "1e3", ""}},
+		{name: "RATIO", values: []string{"0.5", ".5", "5.", "-1e-3", "+2E10", "Inf", "-infinity", "NaN", "1_0", "1_0.5", "1__0", "1_", "e3", "1.2.3", ""}},
+	}
+	for _, tt := range tests {
+		pattern := regexp.MustCompile(js.Properties[tt.name]["pattern"].(string))
+		for _, v := range tt.values {
+			matched := pattern.MatchString(v)
+			parsed := env.Parse(&config{}, env.WithLookuper(env.Map{tt.name: v}), env.WithLogger(nil)) == nil
+			if matched != parsed {
+				t.Errorf("%s=%q: pattern matches %v, Parse accepts %v", tt.name, v, matched, parsed)
+			}
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
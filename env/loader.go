@@ -0,0 +1,46 @@
+package env
+
+import (
+	"reflect"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+type (
+	// Loader resolves the values of struct fields, see envload.Loader. Parse uses one to populate
+	// the fields it walks with reflection.
+	Loader = envload.Loader
+
+	// FieldValue is the resolved value of a struct field.
+	FieldValue = envload.FieldValue
+)
+
+// NewLoader returns a Loader looking variables up with lookup, eg os.LookupEnv, or in a snapshot
+// of the process environment when lookup is nil. The unset option only removes variables from
+// the process environment when lookup is os.LookupEnv or nil.
+func NewLoader(lookup func(string) (string, bool), opts ...Option) (*Loader, error) {
+	return envload.NewLoader(lookup, opts...)
+}
+
+// TagVar returns the Var described by the tag of a struct field. field is the dotted path of
+// the field and typ its Schema type name, see Var.Type. It is used by cmd/envgen.
+func TagVar(field, typ string, tag reflect.StructTag) Var {
+	v := processTag(reflect.StructField{Tag: tag})
+	v.Field, v.Type = field, typ
+	return v
+}
+
+// LoadInterface populates the struct an interface field holds a pointer to, as Parse does, with
+// the names of its variables prefixed by prefix. v is the value of the field and field describes
+// it. Any other value is left as it is. As the type of the struct is only known at run time, it is
+// populated with reflection: the LoadFromEnv methods generated by cmd/envgen only depend on env,
+// and reflection, for interface fields.
+func LoadInterface(l *Loader, v interface{}, field *Var, prefix string) error {
+	e := reflect.ValueOf(v)
+	if e.Kind() == reflect.Ptr && !e.IsNil() && isNested(e.Type()) {
+		p := &parser{l: l}
+		return p.parse(e.Elem(), field.Field, prefix)
+	}
+	_, err := l.Value(field, v != nil)
+	return err
+}
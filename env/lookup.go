@@ -0,0 +1,20 @@
+package env
+
+import (
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+type (
+	// Lookuper looks up environment variables. Parse reads from a snapshot of the process
+	// environment unless WithLookuper is used.
+	Lookuper = envload.Lookuper
+
+	// LookupFunc adapts a function, such as os.LookupEnv, to a Lookuper.
+	LookupFunc = envload.LookupFunc
+
+	// Map is a Lookuper holding the variables in a map, eg to parse tenant specific configuration.
+	Map = envload.Map
+)
+
+// OSLookuper reads the process environment.
+var OSLookuper = envload.OSLookuper
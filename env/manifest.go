@@ -0,0 +1,99 @@
+package env
+
+import (
+	"io"
+	"strings"
+)
+
+// WriteKubernetes writes a ConfigMap and a Secret named name as two YAML documents. Non-secret
+// variables go into the ConfigMap, secret ones into the Secret.
+//
+// The values of the generated manifests are resolved from vars with the same rules as Parse.
+// Missing values are left empty so that every variable is listed. Neither secret references
+// nor encrypted values are resolved.
+func (s *Schema) WriteKubernetes(w io.Writer, name string, vars map[string]string, profile string) error {
+	err := s.WriteConfigMap(w, name, vars, profile)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "---\n")
+	if err != nil {
+		return err
+	}
+	return s.WriteSecret(w, name, vars, profile)
+}
+
+// WriteConfigMap writes a ConfigMap named name holding the non-secret variables.
+func (s *Schema) WriteConfigMap(w io.Writer, name string, vars map[string]string, profile string) error {
+	data := yamlMap{}
+	for _, v := range s.Resolve(vars, profile) {
+		if !v.Var.Secret {
+			data = data.add(v.Var.Name, v.Value)
+		}
+	}
+
+	return writeYAML(w, yamlMap{}.
+		add("apiVersion", "v1").
+		add("kind", "ConfigMap").
+		add("metadata", yamlMap{}.add("name", name)).
+		add("data", data))
+}
+
+// WriteSecret writes an Opaque Secret named name holding the secret variables as stringData.
+func (s *Schema) WriteSecret(w io.Writer, name string, vars map[string]string, profile string) error {
+	data := yamlMap{}
+	for _, v := range s.Resolve(vars, profile) {
+		if v.Var.Secret {
+			data = data.add(v.Var.Name, v.Value)
+		}
+	}
+
+	return writeYAML(w, yamlMap{}.
+		add("apiVersion", "v1").
+		add("kind", "Secret").
+		add("metadata", yamlMap{}.add("name", name)).
+		add("type", "Opaque").
+		add("stringData", data))
+}
+
+// WriteContainerEnv writes the container snippet consuming the ConfigMap and the Secret named name.
+// With envFrom all their keys are imported, otherwise every variable is listed in env with
+// a configMapKeyRef or secretKeyRef.
+func (s *Schema) WriteContainerEnv(w io.Writer, name string, envFrom bool) error {
+	if envFrom {
+		return writeYAML(w, yamlMap{}.add("envFrom", []yamlMap{
+			yamlMap{}.add("configMapRef", yamlMap{}.add("name", name)),
+			yamlMap{}.add("secretRef", yamlMap{}.add("name", name)),
+		}))
+	}
+
+	ee := make([]yamlMap, 0, len(s.Vars))
+	for _, v := range s.Vars {
+		ref := "configMapKeyRef"
+		if v.Secret {
+			ref = "secretKeyRef"
+		}
+		ee = append(ee, yamlMap{}.
+			add("name", v.Name).
+			add("valueFrom", yamlMap{}.add(ref, yamlMap{}.
+				add("name", name).
+				add("key", v.Name))))
+	}
+	return writeYAML(w, yamlMap{}.add("env", ee))
+}
+
+// WriteCompose writes the docker-compose environment block of the service. Secret variables are
+// not written in clear, they are interpolated from the environment docker-compose runs in.
+func (s *Schema) WriteCompose(w io.Writer, service string, vars map[string]string, profile string) error {
+	environment := yamlMap{}
+	for _, v := range s.Resolve(vars, profile) {
+		val := strings.ReplaceAll(v.Value, "$", "$$") // $ starts an interpolation in compose files.
+		if v.Var.Secret {
+			val = "${" + v.Var.Name + "}"
+		}
+		environment = environment.add(v.Var.Name, val)
+	}
+
+	return writeYAML(w, yamlMap{}.add("services", yamlMap{}.
+		add(service, yamlMap{}.add("environment", environment))))
+}
@@ -0,0 +1,158 @@
+package env_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestWriteConfigMap(t *testing.T) {
+	type config struct {
+		Host    string `env:"DB_HOST" envDefault:"localhost"`
+		Debug   bool   `env:"DEBUG" envDefault:"true"`
+		Port    int    `env:"PORT" envDefault:"8080"`
+		Note    string `env:"NOTE"`
+		On      bool   `env:"ON" envDefault:"yes"`
+		No      string `env:"no"`
+		Null    string `env:"NULL" envDefault:"null"`
+		Dotted  string `env:"app.name-x"`
+		Digit   string `env:"1ST"`
+		Special string `env:"A:B"`
+	}
+
+	s, err := env.Describe(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{
+		"NOTE": "line1\nline2 \"quoted\" é",
+		"no":   "a: b # c",
+	}
+
+	var b bytes.Buffer
+	err = s.WriteConfigMap(&b, "app", vars, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `apiVersion: "v1"
+kind: "ConfigMap"
+metadata:
+  name: "app"
+data:
+  DB_HOST: "localhost"
+  DEBUG: "true"
+  PORT: "8080"
+  NOTE: "line1\nline2 \"quoted\" é"
+  "ON": "yes"
+  "no": "a: b # c"
+  "NULL": "null"
+  app.name-x: ""
+  "1ST": ""
+  "A:B": ""
+`
+	if b.String() != want {
+		t.Errorf("WriteConfigMap() wrote\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteYAMLKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "plain", key: "PORT"},
+		{name: "dotted", key: "app.port"},
+		{name: "underscore", key: "_PORT"},
+		{name: "yes", key: `"YES"`},
+		{name: "y", key: `"y"`},
+		{name: "n", key: `"N"`},
+		{name: "no", key: `"No"`},
+		{name: "true", key: `"TRUE"`},
+		{name: "false", key: `"false"`},
+		{name: "on", key: `"On"`},
+		{name: "off", key: `"OFF"`},
+		{name: "null", key: `"Null"`},
+		{name: "word prefix", key: "YES_PLEASE"},
+		{name: "digit", key: `"8080"`},
+		{name: "colon", key: `"A:B"`},
+		{name: "space", key: `"A B"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tt.key
+			if name[0] == '"' {
+				name = name[1 : len(name)-1]
+			}
+			s := &env.Schema{Vars: []env.Var{{Name: name}}}
+
+			var b bytes.Buffer
+			err := s.WriteCompose(&b, "app", nil, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "services:\n  app:\n    environment:\n      " + tt.key + ": \"\"\n"
+			if b.String() != want {
+				t.Errorf("WriteCompose() wrote\n%s\nwant\n%s", b.String(), want)
+			}
+		})
+	}
+}
+
+func TestWriteYAMLEmpty(t *testing.T) {
+	s := &env.Schema{}
+
+	tests := []struct {
+		name  string
+		write func(b *bytes.Buffer) error
+		want  string
+	}{
+		{
+			name:  "empty mapping",
+			write: func(b *bytes.Buffer) error { return s.WriteSecret(b, "app", nil, "") },
+			want: `apiVersion: "v1"
+kind: "Secret"
+metadata:
+  name: "app"
+type: "Opaque"
+stringData: {}
+`,
+		},
+		{
+			name:  "empty nested mapping",
+			write: func(b *bytes.Buffer) error { return s.WriteCompose(b, "yes", nil, "") },
+			want: `services:
+  "yes":
+    environment: {}
+`,
+		},
+		{
+			name:  "empty sequence",
+			write: func(b *bytes.Buffer) error { return s.WriteContainerEnv(b, "app", false) },
+			want:  "env: []\n",
+		},
+		{
+			name:  "sequence of mappings",
+			write: func(b *bytes.Buffer) error { return s.WriteContainerEnv(b, "app", true) },
+			want: `envFrom:
+  - configMapRef:
+      name: "app"
+  - secretRef:
+      name: "app"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := tt.write(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("wrote\n%s\nwant\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
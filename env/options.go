@@ -0,0 +1,72 @@
+package env
+
+import (
+	"context"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+type (
+	// Logger reports parsing progress and warnings, such as the use of a deprecated
+	// environment variable. *log.Logger satisfies it.
+	Logger = envload.Logger
+
+	// Option configures Parse.
+	Option = envload.Option
+)
+
+// DefaultProfileVar is the environment variable holding the active profile unless WithProfile
+// or WithProfileVar is used.
+const DefaultProfileVar = envload.DefaultProfileVar
+
+// WithLogger sets the logger Parse writes to. The standard logger is used by default. With nil, or
+// a *log.Logger writing to io.Discard, the progress of parsing is not logged at all, warnings
+// are still written to a non-nil logger.
+func WithLogger(l Logger) Option {
+	return envload.WithLogger(l)
+}
+
+// WithReport makes Parse record where each field's value came from into r.
+func WithReport(r *Report) Option {
+	return envload.WithReport(r)
+}
+
+// WithProfile sets the active profile, eg dev or prod. Fields tagged envDefault.<profile> take
+// that default instead of the plain envDefault.
+func WithProfile(p string) Option {
+	return envload.WithProfile(p)
+}
+
+// WithProfileVar sets the environment variable the active profile is read from when WithProfile
+// is not used.
+func WithProfileVar(name string) Option {
+	return envload.WithProfileVar(name)
+}
+
+// WithContext sets the context passed to SecretProviders. Parse fails once it is cancelled.
+func WithContext(ctx context.Context) Option {
+	return envload.WithContext(ctx)
+}
+
+// WithSecretProvider uses p to resolve references with the given URI scheme, taking precedence
+// over the providers registered with RegisterSecretProvider.
+func WithSecretProvider(scheme string, p SecretProvider) Option {
+	return envload.WithSecretProvider(scheme, p)
+}
+
+// WithKeyFile reads the key decrypting enc: prefixed values from a file holding the base64
+// encoded key.
+func WithKeyFile(path string) Option {
+	return envload.WithKeyFile(path)
+}
+
+// WithKeyVar sets the environment variable holding the key decrypting enc: prefixed values.
+func WithKeyVar(name string) Option {
+	return envload.WithKeyVar(name)
+}
+
+// WithLookuper makes Parse read the variables from l instead of a snapshot of the process environment.
+// The unset option leaves the variables of l alone, unless l is OSLookuper.
+func WithLookuper(l Lookuper) Option {
+	return envload.WithLookuper(l)
+}
@@ -0,0 +1,108 @@
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestParseTagOptions(t *testing.T) {
+	type config struct {
+		Name    string `env:"NAME,notEmpty"`
+		Region  string `env:"REGION,notEmpty" envDefault:"eu"`
+		Zone    string `env:"ZONE,notEmpty" envDefault:""`
+		Host    string `env:"HOST,noOverwrite" envDefault:"localhost"`
+		Port    int    `env:"PORT, noOverwrite"`
+		Timeout int    `env:"TIMEOUT,required" envDefault:"30"`
+		Token   string `env:"TOKEN,required,notEmpty" envDefault.prod:"prod-token"`
+	}
+
+	tests := []struct {
+		name   string
+		preset config
+		vars   env.Map
+		opts   []env.Option
+		want   config
+		err    string
+	}{
+		{
+			name: "defaults",
+			vars: env.Map{"ZONE": "a", "TOKEN": "t"},
+			want: config{Region: "eu", Zone: "a", Host: "localhost", Timeout: 30, Token: "t"},
+		},
+		{
+			name: "notEmpty unset",
+			vars: env.Map{"ZONE": "a", "TOKEN": "t", "NAME": "app"},
+			want: config{Name: "app", Region: "eu", Zone: "a", Host: "localhost", Timeout: 30, Token: "t"},
+		},
+		{
+			name: "notEmpty empty",
+			vars: env.Map{"ZONE": "a", "TOKEN": "t", "NAME": ""},
+			err:  "Name requires environment variable NAME not to be empty",
+		},
+		{
+			name: "notEmpty empty default",
+			vars: env.Map{"TOKEN": "t"},
+			err:  "Zone requires a non-empty default",
+		},
+		{
+			name:   "noOverwrite keeps preset over default",
+			preset: config{Host: "preset", Port: 1},
+			vars:   env.Map{"ZONE": "a", "TOKEN": "t"},
+			want:   config{Region: "eu", Zone: "a", Host: "preset", Port: 1, Timeout: 30, Token: "t"},
+		},
+		{
+			name:   "noOverwrite variable wins",
+			preset: config{Host: "preset", Port: 1},
+			vars:   env.Map{"ZONE": "a", "TOKEN": "t", "HOST": "db", "PORT": "2"},
+			want:   config{Region: "eu", Zone: "a", Host: "db", Port: 2, Timeout: 30, Token: "t"},
+		},
+		{
+			name:   "preset overwritten without noOverwrite",
+			preset: config{Region: "us"},
+			vars:   env.Map{"ZONE": "a", "TOKEN": "t", "PORT": "2"},
+			want:   config{Region: "eu", Zone: "a", Host: "localhost", Port: 2, Timeout: 30, Token: "t"},
+		},
+		{
+			name: "required by default",
+			vars: env.Map{"ZONE": "a", "TOKEN": "t", "TIMEOUT": "5"},
+			want: config{Region: "eu", Zone: "a", Host: "localhost", Timeout: 5, Token: "t"},
+		},
+		{
+			name: "required without profile default",
+			vars: env.Map{"ZONE": "a"},
+			err:  "Token requires environment variable TOKEN to be set",
+		},
+		{
+			name: "required by profile default",
+			vars: env.Map{"ZONE": "a"},
+			opts: []env.Option{env.WithProfile("prod")},
+			want: config{Region: "eu", Zone: "a", Host: "localhost", Timeout: 30, Token: "prod-token"},
+		},
+		{
+			name: "required and notEmpty",
+			vars: env.Map{"ZONE": "a", "TOKEN": ""},
+			err:  "Token requires environment variable TOKEN not to be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.preset
+			opts := append([]env.Option{env.WithLookuper(tt.vars), env.WithLogger(nil)}, tt.opts...)
+			err := env.Parse(&cfg, opts...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg != tt.want {
+				t.Errorf("config %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package env
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+type (
+	// plan is what Parse needs to know about a struct type, compiled once per type so that parsing
+	// the same type again neither walks its fields nor processes its tags.
+	plan struct {
+		fields []fieldPlan
+	}
+
+	fieldPlan struct {
+		index int
+		name  string
+		v     Var    // processed tags, neither Field nor Type is set.
+		typ   string // Schema type name, see typeName.
+
+		// nested is true for a struct, or a pointer to a struct, parsed field by field.
+		nested bool
+		// embedded is true for an anonymous struct field, whose fields are promoted.
+		embedded bool
+		// prefix is the envPrefix tag of a nested struct, prepended to the names of its variables.
+		prefix string
+		// iface is true for an interface field, populated through the pointer it holds.
+		iface bool
+		ptr   bool
+		elem  reflect.Type                      // type of the field or, for pointers, of the value pointed to.
+		parse func(string) (interface{}, error) // nil when no parser handles the type.
+	}
+)
+
+// plans caches the plan of every struct type parsed.
+var plans sync.Map // reflect.Type -> *plan
+
+// planFor returns the cached plan of the struct type t, compiling it on first use. Plans of
+// nested structs are looked up when they are parsed, which keeps recursive types finite.
+func planFor(t reflect.Type) *plan {
+	if p, ok := plans.Load(t); ok {
+		return p.(*plan)
+	}
+
+	p := &plan{fields: make([]fieldPlan, 0, t.NumField())}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		nested := isNested(sf.Type)
+		// unexported fields cannot be set, but the exported fields of an unexported embedded
+		// struct are promoted and can.
+		if sf.PkgPath != "" && !(sf.Anonymous && nested) {
+			continue
+		}
+
+		fp := fieldPlan{
+			index:    i,
+			name:     sf.Name,
+			v:        processTag(sf),
+			typ:      typeName(sf.Type),
+			nested:   nested,
+			embedded: sf.Anonymous,
+			prefix:   sf.Tag.Get("envPrefix"),
+			iface:    sf.Type.Kind() == reflect.Interface,
+			ptr:      sf.Type.Kind() == reflect.Ptr,
+			elem:     sf.Type,
+		}
+		if fp.ptr {
+			fp.elem = sf.Type.Elem()
+		}
+		fp.parse, _ = envload.Parser(fp.typ)
+		p.fields = append(p.fields, fp)
+	}
+
+	// concurrent first uses compile the same plan, the first one stored wins.
+	actual, _ := plans.LoadOrStore(t, p)
+	return actual.(*plan)
+}
@@ -0,0 +1,89 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestParseProfileDefaults(t *testing.T) {
+	type config struct {
+		Level string `env:"LOG_LEVEL" envDefault:"info" envDefault.dev:"debug" envDefault.prod:"warn"`
+		Port  int    `env:"PORT" envDefault.prod:"443"`
+		Empty string `env:"EMPTY" envDefault:"plain" envDefault.prod:""`
+	}
+
+	tests := []struct {
+		name    string
+		vars    env.Map
+		opts    []env.Option
+		level   string
+		profile string
+		port    int
+		empty   string
+	}{
+		{
+			name:  "no profile",
+			vars:  env.Map{},
+			level: "info",
+			empty: "plain",
+		},
+		{
+			name:    "profile variable",
+			vars:    env.Map{env.DefaultProfileVar: "dev"},
+			level:   "debug",
+			profile: "dev",
+			empty:   "plain",
+		},
+		{
+			name:    "WithProfile",
+			vars:    env.Map{env.DefaultProfileVar: "dev"},
+			opts:    []env.Option{env.WithProfile("prod")},
+			level:   "warn",
+			profile: "prod",
+			port:    443,
+		},
+		{
+			name:    "WithProfileVar",
+			vars:    env.Map{env.DefaultProfileVar: "dev", "STAGE": "prod"},
+			opts:    []env.Option{env.WithProfileVar("STAGE")},
+			level:   "warn",
+			profile: "prod",
+			port:    443,
+		},
+		{
+			name:  "unknown profile",
+			vars:  env.Map{env.DefaultProfileVar: "test"},
+			level: "info",
+			empty: "plain",
+		},
+		{
+			name:  "variable wins",
+			vars:  env.Map{"LOG_LEVEL": "error", "PORT": "8080"},
+			opts:  []env.Option{env.WithProfile("prod")},
+			level: "error",
+			port:  8080,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			report := &env.Report{}
+			opts := append([]env.Option{env.WithLookuper(tt.vars), env.WithReport(report), env.WithLogger(nil)},
+				tt.opts...)
+			err := env.Parse(&cfg, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.Level != tt.level || cfg.Port != tt.port || cfg.Empty != tt.empty {
+				t.Errorf("config %+v, want level %q, port %d, empty %q", cfg, tt.level, tt.port, tt.empty)
+			}
+			for _, f := range report.Fields {
+				if f.Field == "Level" && f.Profile != tt.profile {
+					t.Errorf("Level default of profile %q, want %q", f.Profile, tt.profile)
+				}
+			}
+		})
+	}
+}
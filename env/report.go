@@ -0,0 +1,28 @@
+package env
+
+import (
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+// Source identifies where a field's value came from.
+type Source = envload.Source
+
+const (
+	// SourceNone means neither an environment variable nor a default provided a value.
+	SourceNone = envload.SourceNone
+	// SourceEnv means the value was read from an environment variable.
+	SourceEnv = envload.SourceEnv
+	// SourceDefault means the value was taken from the envDefault tag.
+	SourceDefault = envload.SourceDefault
+	// SourcePreset means the non-zero value set before parsing was kept (noOverwrite option).
+	SourcePreset = envload.SourcePreset
+)
+
+type (
+	// FieldReport records the provenance of a single struct field.
+	FieldReport = envload.FieldReport
+
+	// Report is the provenance report filled in by Parse when WithReport is used. Fields
+	// without an environment variable, eg untagged ones, are left out unless a default sets them.
+	Report = envload.Report
+)
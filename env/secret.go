@@ -0,0 +1,45 @@
+package env
+
+import (
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+// ErrSecretNotFound is returned by the shipped SecretProviders when a reference does not exist.
+var ErrSecretNotFound = envload.ErrSecretNotFound
+
+type (
+	// SecretProvider resolves a secret reference, such as secret://payments/db-password or
+	// vault://kv/app#token, into the secret value.
+	SecretProvider = envload.SecretProvider
+
+	// FileProvider resolves references to files below Dir. secret://payments/db-password is read
+	// from Dir/payments/db-password. A fragment, eg secret://payments/db#password, selects a key
+	// of a file holding a JSON object. Trailing newlines are trimmed.
+	FileProvider = envload.FileProvider
+
+	// MemoryProvider resolves references from memory. It is meant for tests.
+	MemoryProvider = envload.MemoryProvider
+
+	// CachingProvider caches the secrets resolved by another SecretProvider.
+	CachingProvider = envload.CachingProvider
+)
+
+// RegisterSecretProvider makes p resolve environment values with the given URI scheme when parsed.
+// Values with a scheme no provider is registered for are used as they are.
+func RegisterSecretProvider(scheme string, p SecretProvider) {
+	envload.RegisterSecretProvider(scheme, p)
+}
+
+// NewMemoryProvider returns a MemoryProvider holding secrets keyed by their full reference,
+// eg secret://payments/db-password.
+func NewMemoryProvider(secrets map[string]string) *MemoryProvider {
+	return envload.NewMemoryProvider(secrets)
+}
+
+// NewCachingProvider returns a SecretProvider caching what p resolves for ttl. Secrets are cached
+// until Purge is called when ttl is zero. Failures are not cached.
+func NewCachingProvider(p SecretProvider, ttl time.Duration) *CachingProvider {
+	return envload.NewCachingProvider(p, ttl)
+}
@@ -0,0 +1,43 @@
+package env_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+type logRecorder struct {
+	b strings.Builder
+}
+
+func (l *logRecorder) Printf(format string, v ...interface{}) {
+	fmt.Fprintf(&l.b, format, v...)
+}
+
+func TestParseDoesNotLogSecrets(t *testing.T) {
+	type config struct {
+		Pass   string `env:"PASS,secret"`
+		Preset string `env:"PRESET,noOverwrite,secret"`
+		Def    string `env:"DEF,secret" envDefault:"defaultsecret" envDefault.prod:"prodsecret"`
+	}
+
+	cfg := config{Pass: "topsecret", Preset: "presetsecret"}
+	l := &logRecorder{}
+	err := env.Parse(&cfg, env.WithLogger(l), env.WithLookuper(env.Map{"PASS": "newsecret"}), env.WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// parsed again, the fields now hold the secrets.
+	err = env.Parse(&cfg, env.WithLogger(l), env.WithLookuper(env.Map{"PASS": "newsecret"}), env.WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"topsecret", "presetsecret", "newsecret", "defaultsecret", "prodsecret"} {
+		if strings.Contains(l.b.String(), s) {
+			t.Errorf("secret %s logged:\n%s", s, l.b.String())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package env_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestUnsetLeavesLookuperAlone(t *testing.T) {
+	type config struct {
+		U string `env:"U,unset"`
+	}
+
+	t.Setenv("U", "process")
+	m := env.Map{"U": "m"}
+	var cfg config
+	err := env.Parse(&cfg, env.WithLookuper(m), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.U != "m" {
+		t.Errorf("U = %q, want m", cfg.U)
+	}
+	if v, ok := os.LookupEnv("U"); !ok || v != "process" {
+		t.Errorf("process variable U = %q, %t, want it left alone", v, ok)
+	}
+	if m["U"] != "m" {
+		t.Errorf("the caller's map lost U: %v", m)
+	}
+
+	l, err := env.NewLoader(env.Map{"U": "loader"}.LookupEnv, env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.Value(&env.Var{Name: "U", Field: "U", Type: "string", Unset: true}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := os.LookupEnv("U"); !ok {
+		t.Error("Loader with a custom lookup removed the process variable U")
+	}
+}
+
+func TestUnsetProcessEnvironment(t *testing.T) {
+	type config struct {
+		U string `env:"U,unset" envAliases:"U_OLD"`
+	}
+
+	for name, opts := range map[string][]env.Option{
+		"snapshot":   nil,
+		"OSLookuper": {env.WithLookuper(env.OSLookuper)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("U", "process")
+			t.Setenv("U_OLD", "old")
+			var cfg config
+			err := env.Parse(&cfg, append(opts, env.WithLogger(nil))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.U != "process" {
+				t.Errorf("U = %q, want process", cfg.U)
+			}
+			for _, n := range []string{"U", "U_OLD"} {
+				if _, ok := os.LookupEnv(n); ok {
+					t.Errorf("%s is still set", n)
+				}
+			}
+		})
+	}
+}
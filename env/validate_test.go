@@ -0,0 +1,56 @@
+package env_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+func TestValidateParsedValues(t *testing.T) {
+	type config struct {
+		Port    int           `env:"PORT" envMin:"1" envMax:"0xffff"`
+		N       int           `env:"N" envEnum:"1,2"`
+		Size    int64         `env:"SIZE" envMax:"1_000_000"`
+		Ratio   float64       `env:"RATIO" envMin:"0" envMax:"1"`
+		Timeout time.Duration `env:"TIMEOUT" envMin:"1s" envMax:"1m"`
+		Level   string        `env:"LEVEL" envEnum:"debug,info"`
+	}
+
+	tests := []struct {
+		name string
+		vars env.Map
+		err  string
+	}{
+		{name: "hex", vars: env.Map{"PORT": "0x1f90"}},
+		{name: "octal", vars: env.Map{"PORT": "0o17"}},
+		{name: "underscores", vars: env.Map{"SIZE": "1_000"}},
+		{name: "enum leading zero", vars: env.Map{"N": "01"}},
+		{name: "float", vars: env.Map{"RATIO": "0.5"}},
+		{name: "duration", vars: env.Map{"TIMEOUT": "30s"}},
+		{name: "string enum", vars: env.Map{"LEVEL": "info"}},
+		{name: "below min", vars: env.Map{"PORT": "0"}, err: "Port: value 0 is less than 1"},
+		{name: "above max", vars: env.Map{"PORT": "0x10000"}, err: "Port: value 0x10000 is greater than 0xffff"},
+		{name: "not in enum", vars: env.Map{"N": "3"}, err: "N: value 3 is not one of 1, 2"},
+		{name: "above max underscores", vars: env.Map{"SIZE": "1_000_001"}, err: "greater than 1_000_000"},
+		{name: "duration above max", vars: env.Map{"TIMEOUT": "2m"}, err: "greater than 1m"},
+		{name: "string not in enum", vars: env.Map{"LEVEL": "trace"}, err: "not one of debug, info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			err := env.Parse(&cfg, env.WithLookuper(tt.vars), env.WithLogger(discard{}))
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+type discard struct{}
+
+func (discard) Printf(string, ...interface{}) {}
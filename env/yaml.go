@@ -0,0 +1,104 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A minimal YAML writer for the manifests generated from a Schema. It supports mappings
+// with ordered keys, sequences of mappings and string scalars, which is all the manifests need.
+type (
+	yamlMap []yamlEntry
+
+	yamlEntry struct {
+		key   string
+		value interface{} // string, yamlMap or []yamlMap
+	}
+)
+
+var (
+	plainYAMLKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+	// yamlWords are the plain scalars that YAML 1.1 parsers, eg the one of Kubernetes, read as
+	// booleans or null, in lower case.
+	yamlWords = map[string]bool{
+		"y": true, "yes": true, "n": true, "no": true,
+		"true": true, "false": true, "on": true, "off": true,
+		"null": true,
+	}
+)
+
+func (m yamlMap) add(key string, value interface{}) yamlMap {
+	return append(m, yamlEntry{key: key, value: value})
+}
+
+// writeYAML writes a YAML document.
+func writeYAML(w io.Writer, m yamlMap) error {
+	yw := &yamlWriter{w: w}
+	yw.mapping(m, 0, false)
+	return yw.err
+}
+
+type yamlWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (yw *yamlWriter) printf(format string, a ...interface{}) {
+	if yw.err != nil {
+		return
+	}
+	_, yw.err = fmt.Fprintf(yw.w, format, a...)
+}
+
+// mapping writes m indented by level. When inList is true the first key follows a sequence dash
+// already written.
+func (yw *yamlWriter) mapping(m yamlMap, level int, inList bool) {
+	for i, e := range m {
+		indent := strings.Repeat("  ", level)
+		if inList && i == 0 {
+			indent = ""
+		}
+		key := yamlKey(e.key)
+
+		switch v := e.value.(type) {
+		case string:
+			yw.printf("%s%s: %s\n", indent, key, yamlString(v))
+		case yamlMap:
+			if len(v) == 0 {
+				yw.printf("%s%s: {}\n", indent, key)
+				continue
+			}
+			yw.printf("%s%s:\n", indent, key)
+			yw.mapping(v, level+1, false)
+		case []yamlMap:
+			if len(v) == 0 {
+				yw.printf("%s%s: []\n", indent, key)
+				continue
+			}
+			yw.printf("%s%s:\n", indent, key)
+			for _, item := range v {
+				yw.printf("%s  - ", strings.Repeat("  ", level))
+				yw.mapping(item, level+2, true)
+			}
+		default:
+			yw.err = fmt.Errorf("unsupported YAML value %T", v)
+		}
+	}
+}
+
+func yamlKey(k string) string {
+	if plainYAMLKey.MatchString(k) && !yamlWords[strings.ToLower(k)] {
+		return k
+	}
+	return yamlString(k)
+}
+
+// yamlString double quotes a scalar. Go escapes are a subset of the YAML double quoted escapes,
+// quoting also keeps values such as true or 8080 strings, as ConfigMap data requires.
+func yamlString(s string) string {
+	return strconv.Quote(s)
+}
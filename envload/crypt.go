@@ -0,0 +1,133 @@
+package envload
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// EncryptedPrefix marks an environment value holding AES-GCM ciphertext produced by Encrypt.
+	EncryptedPrefix = "enc:"
+	// DefaultKeyVar is the environment variable holding the decryption key unless WithKeyFile
+	// or WithKeyVar is used.
+	DefaultKeyVar = "ENV_ENCRYPTION_KEY"
+)
+
+// ErrNoKey is returned when an encrypted value is found but no decryption key is available.
+var ErrNoKey = errors.New("no decryption key")
+
+// GenerateKey returns a new random 256 bit key, base64 encoded as expected by ParseKey.
+func GenerateKey() (string, error) {
+	k := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, k); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(k), nil
+}
+
+// ParseKey decodes a base64 encoded AES key of 16, 24 or 32 bytes.
+func ParseKey(s string) ([]byte, error) {
+	k, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	switch len(k) {
+	case 16, 24, 32:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("invalid key: length %d, expected 16, 24 or 32 bytes", len(k))
+	}
+}
+
+// Encrypt seals plaintext with AES-GCM and returns it as an enc: prefixed value that the Loader
+// decrypts.
+func Encrypt(key []byte, plaintext string) (string, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens an enc: prefixed value produced by Encrypt.
+func Decrypt(key []byte, val string) (string, error) {
+	if !strings.HasPrefix(val, EncryptedPrefix) {
+		return "", fmt.Errorf("value is not prefixed with %s", EncryptedPrefix)
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(val, EncryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	if len(b) < aead.NonceSize() {
+		return "", errors.New("invalid encrypted value: too short")
+	}
+
+	p, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+	if err != nil {
+		// the error from cipher.AEAD does not carry anything sensitive, it is replaced to be explicit.
+		return "", errors.New("failed to decrypt value: wrong key or corrupted value")
+	}
+	return string(p), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(b)
+}
+
+// decryptionKey returns the key from the key file, if set, or the key variable. The key is loaded
+// once per Loader, when the first encrypted value is found.
+func (o *options) decryptionKey() ([]byte, error) {
+	if o.key != nil {
+		return o.key, nil
+	}
+
+	var (
+		s   string
+		err error
+	)
+	switch {
+	case o.keyFile != "":
+		var b []byte
+		b, err = os.ReadFile(o.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		s = string(b)
+	default:
+		var ok bool
+		s, ok = o.lookuper.LookupEnv(o.keyVar)
+		if !ok {
+			return nil, fmt.Errorf("%w: set %s or use a key file", ErrNoKey, o.keyVar)
+		}
+	}
+
+	o.key, err = ParseKey(s)
+	if err != nil {
+		return nil, err
+	}
+	return o.key, nil
+}
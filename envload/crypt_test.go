@@ -0,0 +1,190 @@
+package envload_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+func mustKey(t *testing.T) []byte {
+	t.Helper()
+	s, err := envload.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := envload.ParseKey(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := mustKey(t)
+
+	tests := []struct {
+		name      string
+		plaintext string
+	}{
+		{name: "password", plaintext: "s3cret"},
+		{name: "empty", plaintext: ""},
+		{name: "unicode", plaintext: "héslo 🔑"},
+		{name: "long", plaintext: strings.Repeat("x", 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := envload.Encrypt(key, tt.plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(val, envload.EncryptedPrefix) {
+				t.Errorf("Encrypt() = %q, want it prefixed %s", val, envload.EncryptedPrefix)
+			}
+			if tt.plaintext != "" && strings.Contains(val, tt.plaintext) {
+				t.Errorf("Encrypt() = %q holds the plaintext", val)
+			}
+			got, err := envload.Decrypt(key, val)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.plaintext {
+				t.Errorf("Decrypt() = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+
+	a, _ := envload.Encrypt(key, "same")
+	b, _ := envload.Encrypt(key, "same")
+	if a == b {
+		t.Errorf("encrypting the same plaintext twice gave %q both times, want a fresh nonce", a)
+	}
+}
+
+func TestDecryptInvalid(t *testing.T) {
+	key := mustKey(t)
+	val, err := envload.Encrypt(key, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(val, envload.EncryptedPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(b []byte) string {
+		return envload.EncryptedPrefix + base64.StdEncoding.EncodeToString(b)
+	}
+	flipped := append([]byte(nil), sealed...)
+	flipped[len(flipped)-1] ^= 1
+
+	tests := []struct {
+		name string
+		key  []byte
+		val  string
+		err  string
+	}{
+		{name: "wrong key", key: mustKey(t), val: val, err: "wrong key or corrupted value"},
+		{name: "invalid key", key: []byte("short"), val: val, err: "invalid key size"},
+		{name: "no prefix", key: key, val: strings.TrimPrefix(val, envload.EncryptedPrefix), err: "not prefixed"},
+		{name: "bad base64", key: key, val: "enc:not base64!", err: "invalid encrypted value: illegal base64"},
+		{name: "empty", key: key, val: "enc:", err: "invalid encrypted value: too short"},
+		{name: "shorter than nonce", key: key, val: encode(sealed[:8]), err: "invalid encrypted value: too short"},
+		{name: "truncated", key: key, val: encode(sealed[:len(sealed)-4]), err: "wrong key or corrupted value"},
+		{name: "nonce only", key: key, val: encode(sealed[:12]), err: "wrong key or corrupted value"},
+		{name: "corrupted", key: key, val: encode(flipped), err: "wrong key or corrupted value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := envload.Decrypt(tt.key, tt.val)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("Decrypt() = %q, %v, want an error containing %q", got, err, tt.err)
+			}
+			if strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error %v holds the plaintext", err)
+			}
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := func(n int) string {
+		return base64.StdEncoding.EncodeToString(make([]byte, n))
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		len  int
+		err  string
+	}{
+		{name: "AES-128", key: key(16), len: 16},
+		{name: "AES-192", key: key(24), len: 24},
+		{name: "AES-256", key: key(32), len: 32},
+		{name: "trailing newline", key: key(32) + "\n", len: 32},
+		{name: "wrong length", key: key(20), err: "length 20"},
+		{name: "empty", key: "", err: "length 0"},
+		{name: "bad base64", key: "not a key", err: "invalid key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := envload.ParseKey(tt.key)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseKey() error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(k) != tt.len {
+				t.Errorf("ParseKey() returned %d bytes, want %d", len(k), tt.len)
+			}
+		})
+	}
+}
+
+func TestLoaderDecrypt(t *testing.T) {
+	keyStr, err := envload.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := envload.ParseKey(keyStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, err := envload.Encrypt(key, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		vars  envload.Map
+		value string
+		err   error
+	}{
+		{name: "key variable", vars: envload.Map{"PASSWORD": val, envload.DefaultKeyVar: keyStr}, value: "s3cret"},
+		{name: "no key", vars: envload.Map{"PASSWORD": val}, err: envload.ErrNoKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := envload.NewLoader(tt.vars.LookupEnv, envload.WithLogger(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			fv, err := l.Value(&envload.Var{Name: "PASSWORD", Field: "Password"}, false)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Value() error %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if fv.Value != tt.value || !fv.Secret {
+				t.Errorf("Value() = %q, secret %t, want %q, secret", fv.Value, fv.Secret, tt.value)
+			}
+		})
+	}
+}
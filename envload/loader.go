@@ -0,0 +1,150 @@
+// Package envload resolves the values of environment variables described by a Var, following the
+// rules of the env tags: aliases, deprecated names, profile specific defaults, required, notEmpty,
+// unset and noOverwrite options, enum and range constraints, secret references and encrypted
+// values. It does not use reflection, which leaves walking structs to its callers: env.Parse
+// walks them with reflection, the LoadFromEnv methods generated by cmd/envgen assign the fields
+// directly.
+package envload
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type (
+	// Loader resolves the values of struct fields.
+	Loader struct {
+		o *options
+	}
+
+	// FieldValue is the resolved value of a struct field.
+	FieldValue struct {
+		Value string
+		// Secret is true when the value must not be logged.
+		Secret bool
+	}
+)
+
+// NewLoader returns a Loader looking variables up with lookup, eg os.LookupEnv, or in a snapshot
+// of the process environment when lookup is nil. The unset option only removes variables from
+// the process environment when lookup is os.LookupEnv or nil.
+func NewLoader(lookup func(string) (string, bool), opts ...Option) (*Loader, error) {
+	if lookup != nil {
+		opts = append([]Option{WithLookuper(LookupFunc(lookup))}, opts...)
+	}
+	o := newOptions(opts)
+	err := o.init()
+	if err != nil {
+		return nil, err
+	}
+	return &Loader{o: o}, nil
+}
+
+// Value resolves the value of the field described by v. preset tells whether the field is non-zero.
+// It returns nil when the field is to be left as it is.
+func (l *Loader) Value(v *Var, preset bool) (*FieldValue, error) {
+	name := v.Field
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if l.o.trace {
+		l.o.logger.Printf("\t\tTag Info: [%s]\n", v.logString())
+	}
+	return l.o.value(v, name, preset)
+}
+
+// Tracef logs the progress of loading, unless the logger discards it.
+func (l *Loader) Tracef(format string, args ...interface{}) {
+	if l.o.trace {
+		l.o.logger.Printf(format, args...)
+	}
+}
+
+// Tracing tells whether the progress of loading is logged, so that callers can skip formatting it.
+func (l *Loader) Tracing() bool {
+	return l.o.trace
+}
+
+// value resolves the value of the field described by v. name is the field name used in errors and
+// preset tells whether the field is non-zero.
+//
+// Values referencing a secret, eg secret://payments/db-password, are resolved by the SecretProvider
+// registered for the scheme. Values prefixed enc: are decrypted.
+func (o *options) value(v *Var, name string, preset bool) (*FieldValue, error) {
+	val, fr, err := v.resolve(name, o.lookuper, o.profile, preset)
+	// variables looked up elsewhere, eg in a Map of the caller, are left alone.
+	if v.Unset && o.processEnv {
+		for _, n := range v.Names() {
+			os.Unsetenv(n)
+			if o.snapshot != nil {
+				delete(o.snapshot, n)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	fr.Field = v.Field
+
+	switch fr.Source {
+	case SourcePreset, SourceNone:
+		// fields without a variable, eg untagged ones, have no provenance to report.
+		if v.Name != "" || fr.Source == SourcePreset {
+			o.report.add(fr)
+		}
+		return nil, nil
+	case SourceEnv:
+		if o.trace {
+			o.logger.Printf("\t\tEnv Info: [%s]\n", mask(val, v.Secret))
+		}
+		if fr.Deprecated {
+			o.logger.Printf("WARNING: %s is set from deprecated environment variable %s, use %s instead\n",
+				v.Field, fr.Var, v.Name)
+		}
+	}
+
+	fv := &FieldValue{Value: val, Secret: v.Secret}
+	if strings.HasPrefix(val, EncryptedPrefix) {
+		key, err := o.decryptionKey()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fv.Value, err = Decrypt(key, val)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fv.Secret = true
+		fr.Secret, fr.Encrypted = true, true
+	}
+
+	ref, p, err := o.secretRef(val)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil && !fr.Encrypted {
+		if o.trace {
+			o.logger.Printf("\t\tResolving secret: [%s]\n", ref)
+		}
+		fv.Value, err = p.Resolve(o.ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to resolve secret %s: %w", name, ref, err)
+		}
+		fv.Secret = true
+		fr.Secret, fr.SecretRef = true, ref.String()
+	}
+
+	err = v.validate(name, fv.Value, fv.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	o.report.add(fr)
+	return fv, nil
+}
+
+// ParseError returns the error reported when the value cannot be parsed into the type of
+// the field.
+func (fv *FieldValue) ParseError(field string) error {
+	return fmt.Errorf("failed to parse value %s for field %s", mask(fv.Value, fv.Secret), field)
+}
@@ -0,0 +1,20 @@
+package envload_test
+
+import (
+	"go/build"
+	"testing"
+)
+
+// TestNoReflect checks that envload, which the loaders generated by cmd/envgen depend on, does not
+// import reflect.
+func TestNoReflect(t *testing.T) {
+	pkg, err := build.Import("github.com/tamarakaufler/go-and-reflect/envload", ".", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, imp := range pkg.Imports {
+		if imp == "reflect" {
+			t.Errorf("envload imports reflect")
+		}
+	}
+}
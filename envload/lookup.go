@@ -0,0 +1,46 @@
+package envload
+
+import (
+	"os"
+	"strings"
+)
+
+type (
+	// Lookuper looks up environment variables. The Loader reads from a snapshot of the process
+	// environment unless WithLookuper is used.
+	Lookuper interface {
+		LookupEnv(name string) (string, bool)
+	}
+
+	// LookupFunc adapts a function, such as os.LookupEnv, to a Lookuper.
+	LookupFunc func(name string) (string, bool)
+
+	// Map is a Lookuper holding the variables in a map, eg to parse tenant specific configuration.
+	Map map[string]string
+)
+
+// OSLookuper reads the process environment.
+var OSLookuper Lookuper = LookupFunc(os.LookupEnv)
+
+// Environ returns a copy of the process environment.
+func Environ() map[string]string {
+	envs := os.Environ()
+
+	envM := make(map[string]string, len(envs))
+	for _, e := range envs {
+		p := strings.SplitN(e, "=", 2)
+		envM[p[0]] = p[1]
+	}
+	return envM
+}
+
+// LookupEnv implements Lookuper.
+func (f LookupFunc) LookupEnv(name string) (string, bool) {
+	return f(name)
+}
+
+// LookupEnv implements Lookuper.
+func (m Map) LookupEnv(name string) (string, bool) {
+	v, ok := m[name]
+	return v, ok
+}
@@ -0,0 +1,189 @@
+package envload
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"time"
+	"unsafe"
+)
+
+type (
+	// Logger reports loading progress and warnings, such as the use of a deprecated
+	// environment variable. *log.Logger satisfies it.
+	Logger interface {
+		Printf(format string, v ...interface{})
+	}
+
+	// Option configures a Loader.
+	Option func(*options)
+
+	options struct {
+		logger Logger
+		// trace is false when the logger discards what it is given, the progress of loading is
+		// then not even formatted.
+		trace      bool
+		report     *Report
+		profile    string
+		profileVar string
+		ctx        context.Context
+		providers  map[string]SecretProvider
+		keyFile    string
+		keyVar     string
+		key        []byte
+		lookuper   Lookuper
+		// processEnv is true when the lookuper reads the process environment, which the unset
+		// option then removes variables from. snapshot is the copy of it taken by init.
+		processEnv bool
+		snapshot   Map
+	}
+)
+
+// DefaultProfileVar is the environment variable holding the active profile unless WithProfile
+// or WithProfileVar is used.
+const DefaultProfileVar = "APP_PROFILE"
+
+// WithLogger sets the logger the Loader writes to. The standard logger is used by default. With nil,
+// or a *log.Logger writing to io.Discard, the progress of loading is not logged at all, warnings
+// are still written to a non-nil logger.
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithReport makes the Loader record where each field's value came from into r.
+func WithReport(r *Report) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// WithProfile sets the active profile, eg dev or prod. Fields tagged envDefault.<profile> take
+// that default instead of the plain envDefault.
+func WithProfile(p string) Option {
+	return func(o *options) {
+		o.profile = p
+	}
+}
+
+// WithProfileVar sets the environment variable the active profile is read from when WithProfile
+// is not used.
+func WithProfileVar(name string) Option {
+	return func(o *options) {
+		o.profileVar = name
+	}
+}
+
+// WithContext sets the context passed to SecretProviders. Loading fails once it is cancelled.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithSecretProvider uses p to resolve references with the given URI scheme, taking precedence
+// over the providers registered with RegisterSecretProvider.
+func WithSecretProvider(scheme string, p SecretProvider) Option {
+	return func(o *options) {
+		if o.providers == nil {
+			o.providers = make(map[string]SecretProvider)
+		}
+		o.providers[scheme] = p
+	}
+}
+
+// WithKeyFile reads the key decrypting enc: prefixed values from a file holding the base64
+// encoded key.
+func WithKeyFile(path string) Option {
+	return func(o *options) {
+		o.keyFile = path
+	}
+}
+
+// WithKeyVar sets the environment variable holding the key decrypting enc: prefixed values.
+func WithKeyVar(name string) Option {
+	return func(o *options) {
+		o.keyVar = name
+	}
+}
+
+// WithLookuper makes the Loader read the variables from l instead of a snapshot of the process
+// environment. The unset option leaves the variables of l alone, unless l is OSLookuper.
+func WithLookuper(l Lookuper) Option {
+	return func(o *options) {
+		o.lookuper = l
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		logger:     log.Default(),
+		profileVar: DefaultProfileVar,
+		ctx:        context.Background(),
+		keyVar:     DefaultKeyVar,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.logger == nil {
+		o.logger = log.New(io.Discard, "", 0)
+	}
+	o.trace = !discards(o.logger)
+	return o
+}
+
+// init prepares the options for loading: it snapshots the process environment unless a Lookuper
+// is set and determines the active profile.
+func (o *options) init() error {
+	if err := o.ctx.Err(); err != nil {
+		return err
+	}
+
+	if o.lookuper == nil {
+		o.snapshot = Map(Environ())
+		o.lookuper = o.snapshot
+		o.processEnv = true
+	} else {
+		o.processEnv = isProcessEnv(o.lookuper)
+	}
+	if o.profile == "" {
+		o.profile, _ = o.lookuper.LookupEnv(o.profileVar)
+	}
+	if o.trace {
+		o.logger.Printf("Profile: [%s]\n", o.profile)
+	}
+
+	if o.report != nil {
+		o.report.LoadedAt = time.Now()
+	}
+	return nil
+}
+
+// isProcessEnv tells whether l reads the process environment through os.LookupEnv.
+func isProcessEnv(l Lookuper) bool {
+	f, ok := l.(LookupFunc)
+	if !ok || f == nil {
+		return false
+	}
+	return codePointer(f) == codePointer(os.LookupEnv)
+}
+
+// codePointer returns the address of the code of f, which functions are compared by. A func value
+// points to the address of its code, as reflect.Value.Pointer reads it.
+func codePointer(f func(string) (string, bool)) uintptr {
+	return **(**uintptr)(unsafe.Pointer(&f)) //nolint:gosec // the layout of func values.
+}
+
+// discards tells whether the logger writes nowhere.
+func discards(l Logger) bool {
+	ll, ok := l.(*log.Logger)
+	return ok && ll.Writer() == io.Discard
+}
+
+// Profile returns the profile set by WithProfile among opts, empty when there is none. Unlike
+// a Loader, it does not read the profile from the environment.
+func Profile(opts ...Option) string {
+	return newOptions(opts).profile
+}
@@ -0,0 +1,95 @@
+package envload
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Source identifies where a field's value came from.
+type Source string
+
+const (
+	// SourceNone means neither an environment variable nor a default provided a value.
+	SourceNone Source = "none"
+	// SourceEnv means the value was read from an environment variable.
+	SourceEnv Source = "env"
+	// SourceDefault means the value was taken from the envDefault tag.
+	SourceDefault Source = "default"
+	// SourcePreset means the non-zero value set before parsing was kept (noOverwrite option).
+	SourcePreset Source = "preset"
+)
+
+type (
+	// FieldReport records the provenance of a single struct field.
+	FieldReport struct {
+		// Field is the dotted path of the field from the parsed struct, eg Address.Street.
+		Field string `json:"field"`
+		// Var is the environment variable the value was read from. It is empty unless Source is SourceEnv.
+		Var string `json:"var,omitempty"`
+		// Source tells where the value came from.
+		Source Source `json:"source"`
+		// Profile is the profile whose default was used. It is empty for the plain envDefault.
+		Profile string `json:"profile,omitempty"`
+		// Deprecated is true when Var is one of the field's deprecated names.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Secret is true when the field is tagged secret or its value was resolved by a SecretProvider.
+		Secret bool `json:"secret,omitempty"`
+		// Encrypted is true when the value was decrypted from an enc: prefixed value.
+		Encrypted bool `json:"encrypted,omitempty"`
+		// SecretRef is the reference the value was resolved from, eg secret://payments/db-password.
+		SecretRef string `json:"secretRef,omitempty"`
+	}
+
+	// Report is the provenance report filled in by the Loader when WithReport is used. Fields
+	// without an environment variable, eg untagged ones, are left out unless a default sets them.
+	Report struct {
+		Fields []FieldReport `json:"fields"`
+		// LoadedAt is the time the configuration was parsed.
+		LoadedAt time.Time `json:"loadedAt"`
+	}
+)
+
+// Deprecated returns the fields whose values were read from deprecated environment variables.
+func (r *Report) Deprecated() []FieldReport {
+	var dd []FieldReport
+	for _, f := range r.Fields {
+		if f.Deprecated {
+			dd = append(dd, f)
+		}
+	}
+	return dd
+}
+
+// Print writes the report in a human readable form.
+func (r *Report) Print(w io.Writer) error {
+	for _, f := range r.Fields {
+		src := string(f.Source)
+		if f.Profile != "" {
+			src = fmt.Sprintf("%s (%s)", src, f.Profile)
+		}
+		if f.Var != "" {
+			src = fmt.Sprintf("%s %s", src, f.Var)
+		}
+		if f.Deprecated {
+			src += " (deprecated)"
+		}
+		if f.Encrypted {
+			src += " (encrypted)"
+		}
+		if f.SecretRef != "" {
+			src = fmt.Sprintf("%s -> %s", src, f.SecretRef)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", f.Field, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Report) add(fr FieldReport) {
+	if r == nil {
+		return
+	}
+	r.Fields = append(r.Fields, fr)
+}
@@ -0,0 +1,223 @@
+package envload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSecretNotFound is returned by the shipped SecretProviders when a reference does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves a secret reference, such as secret://payments/db-password or
+// vault://kv/app#token, into the secret value.
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]SecretProvider{}
+)
+
+// RegisterSecretProvider makes p resolve environment values with the given URI scheme when loaded.
+// Values with a scheme no provider is registered for are used as they are.
+func RegisterSecretProvider(scheme string, p SecretProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if p == nil {
+		delete(providers, scheme)
+		return
+	}
+	providers[scheme] = p
+}
+
+// secretRef returns the parsed reference and its provider if val references a secret.
+func (o *options) secretRef(val string) (*url.URL, SecretProvider, error) {
+	i := strings.Index(val, "://")
+	if i <= 0 {
+		return nil, nil, nil
+	}
+	scheme := val[:i]
+
+	p, ok := o.providers[scheme]
+	if !ok {
+		providersMu.RLock()
+		p, ok = providers[scheme]
+		providersMu.RUnlock()
+	}
+	if !ok {
+		return nil, nil, nil
+	}
+
+	ref, err := url.Parse(val)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid secret reference: %w", err)
+	}
+	return ref, p, nil
+}
+
+// registeredSecret tells whether val references a secret of a scheme registered with
+// RegisterSecretProvider.
+func registeredSecret(val string) bool {
+	i := strings.Index(val, "://")
+	if i <= 0 {
+		return false
+	}
+
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	_, ok := providers[val[:i]]
+	return ok
+}
+
+// FileProvider resolves references to files below Dir. secret://payments/db-password is read
+// from Dir/payments/db-password. A fragment, eg secret://payments/db#password, selects a key
+// of a file holding a JSON object. Trailing newlines are trimmed.
+type FileProvider struct {
+	Dir string
+}
+
+// Resolve implements SecretProvider.
+func (p FileProvider) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	root := filepath.Clean(p.Dir)
+	name := filepath.Join(root, ref.Host, filepath.FromSlash(ref.Path))
+	if name != root && !strings.HasPrefix(name, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("secret %s is outside %s", ref, p.Dir)
+	}
+
+	b, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, ref)
+	}
+	if err != nil {
+		return "", err
+	}
+	if ref.Fragment == "" {
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", ref, err)
+	}
+	v, ok := m[ref.Fragment]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, ref)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+// MemoryProvider resolves references from memory. It is meant for tests.
+type MemoryProvider struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+// NewMemoryProvider returns a MemoryProvider holding secrets keyed by their full reference,
+// eg secret://payments/db-password.
+func NewMemoryProvider(secrets map[string]string) *MemoryProvider {
+	p := &MemoryProvider{secrets: make(map[string]string, len(secrets))}
+	for k, v := range secrets {
+		p.secrets[k] = v
+	}
+	return p
+}
+
+// Set stores a secret under its full reference.
+func (p *MemoryProvider) Set(ref, val string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.secrets[ref] = val
+}
+
+// Resolve implements SecretProvider.
+func (p *MemoryProvider) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	v, ok := p.secrets[ref.String()]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, ref)
+	}
+	return v, nil
+}
+
+type (
+	// CachingProvider caches the secrets resolved by another SecretProvider.
+	CachingProvider struct {
+		p   SecretProvider
+		ttl time.Duration
+
+		mu    sync.Mutex
+		cache map[string]cachedSecret
+	}
+
+	cachedSecret struct {
+		val     string
+		expires time.Time
+	}
+)
+
+// NewCachingProvider returns a SecretProvider caching what p resolves for ttl. Secrets are cached
+// until Purge is called when ttl is zero. Failures are not cached.
+func NewCachingProvider(p SecretProvider, ttl time.Duration) *CachingProvider {
+	return &CachingProvider{
+		p:     p,
+		ttl:   ttl,
+		cache: map[string]cachedSecret{},
+	}
+}
+
+// Resolve implements SecretProvider.
+func (c *CachingProvider) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	key := ref.String()
+
+	c.mu.Lock()
+	cs, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && (cs.expires.IsZero() || time.Now().Before(cs.expires)) {
+		return cs.val, nil
+	}
+
+	v, err := c.p.Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	cs = cachedSecret{val: v}
+	if c.ttl > 0 {
+		cs.expires = time.Now().Add(c.ttl)
+	}
+	c.mu.Lock()
+	c.cache[key] = cs
+	c.mu.Unlock()
+
+	return v, nil
+}
+
+// Purge drops all cached secrets.
+func (c *CachingProvider) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache = map[string]cachedSecret{}
+}
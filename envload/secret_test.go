@@ -0,0 +1,228 @@
+package envload_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/envload"
+)
+
+func mustRef(t *testing.T, s string) *url.URL {
+	t.Helper()
+	ref, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func TestFileProvider(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "secrets")
+	files := map[string]string{
+		"payments/db-password": "s3cret\n",
+		"payments/db":          `{"user": "app", "password": "p4ss", "port": 5432}`,
+		"payments/plain":       "not json",
+		"../outside":           "leaked",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := envload.FileProvider{Dir: dir}
+
+	tests := []struct {
+		name string
+		ref  string
+		val  string
+		err  string
+	}{
+		{name: "file", ref: "secret://payments/db-password", val: "s3cret"},
+		{name: "fragment", ref: "secret://payments/db#password", val: "p4ss"},
+		{name: "fragment number", ref: "secret://payments/db#port", val: "5432"},
+		{name: "missing fragment", ref: "secret://payments/db#token", err: envload.ErrSecretNotFound.Error()},
+		{name: "fragment of a plain file", ref: "secret://payments/plain#password", err: "not a JSON object"},
+		{name: "missing file", ref: "secret://payments/missing", err: envload.ErrSecretNotFound.Error()},
+		{name: "parent host", ref: "secret://../outside", err: "outside"},
+		{name: "parent path", ref: "secret://payments/../../outside", err: "outside"},
+		{name: "encoded parent path", ref: "secret://payments/%2E%2E/%2E%2E/outside", err: "outside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := p.Resolve(context.Background(), mustRef(t, tt.ref))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Resolve(%s) = %q, %v, want an error containing %q", tt.ref, val, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if val != tt.val {
+				t.Errorf("Resolve(%s) = %q, want %q", tt.ref, val, tt.val)
+			}
+		})
+	}
+}
+
+// countingProvider counts the secrets it resolves.
+type countingProvider struct {
+	calls int32
+	err   error
+}
+
+func (p *countingProvider) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	atomic.AddInt32(&p.calls, 1)
+	if p.err != nil {
+		return "", p.err
+	}
+	return ref.Host, nil
+}
+
+func TestCachingProvider(t *testing.T) {
+	ctx := context.Background()
+	ref := mustRef(t, "secret://token")
+
+	resolve := func(t *testing.T, c *envload.CachingProvider) {
+		t.Helper()
+		val, err := c.Resolve(ctx, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val != "token" {
+			t.Fatalf("Resolve() = %q, want token", val)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		steps func(t *testing.T, c *envload.CachingProvider)
+		calls int32
+	}{
+		{
+			name: "cached",
+			ttl:  time.Hour,
+			steps: func(t *testing.T, c *envload.CachingProvider) {
+				resolve(t, c)
+				resolve(t, c)
+			},
+			calls: 1,
+		},
+		{
+			name: "expired",
+			ttl:  10 * time.Millisecond,
+			steps: func(t *testing.T, c *envload.CachingProvider) {
+				resolve(t, c)
+				time.Sleep(20 * time.Millisecond)
+				resolve(t, c)
+				resolve(t, c)
+			},
+			calls: 2,
+		},
+		{
+			name: "no ttl",
+			steps: func(t *testing.T, c *envload.CachingProvider) {
+				resolve(t, c)
+				time.Sleep(20 * time.Millisecond)
+				resolve(t, c)
+			},
+			calls: 1,
+		},
+		{
+			name: "purged",
+			steps: func(t *testing.T, c *envload.CachingProvider) {
+				resolve(t, c)
+				c.Purge()
+				resolve(t, c)
+				resolve(t, c)
+			},
+			calls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &countingProvider{}
+			tt.steps(t, envload.NewCachingProvider(p, tt.ttl))
+			if p.calls != tt.calls {
+				t.Errorf("the provider resolved %d times, want %d", p.calls, tt.calls)
+			}
+		})
+	}
+
+	t.Run("failures", func(t *testing.T) {
+		p := &countingProvider{err: envload.ErrSecretNotFound}
+		c := envload.NewCachingProvider(p, time.Hour)
+		for i := 0; i < 2; i++ {
+			if _, err := c.Resolve(ctx, ref); !errors.Is(err, envload.ErrSecretNotFound) {
+				t.Fatalf("Resolve() error %v, want %v", err, envload.ErrSecretNotFound)
+			}
+		}
+		if p.calls != 2 {
+			t.Errorf("the provider resolved %d times, want 2 as failures are not cached", p.calls)
+		}
+	})
+}
+
+func TestSecretProviderCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("s3cret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		p    envload.SecretProvider
+	}{
+		{name: "file", p: envload.FileProvider{Dir: dir}},
+		{name: "memory", p: envload.NewMemoryProvider(map[string]string{"secret://token": "s3cret"})},
+		{name: "caching", p: envload.NewCachingProvider(envload.FileProvider{Dir: dir}, time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := tt.p.Resolve(ctx, mustRef(t, "secret://token"))
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Resolve() = %q, %v, want %v", val, err, context.Canceled)
+			}
+		})
+	}
+
+	t.Run("loader", func(t *testing.T) {
+		lookup := envload.Map{"TOKEN": "secret://token"}.LookupEnv
+		opts := []envload.Option{envload.WithSecretProvider("secret", envload.FileProvider{Dir: dir}),
+			envload.WithLogger(nil)}
+
+		_, err := envload.NewLoader(lookup, append(opts, envload.WithContext(ctx))...)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("NewLoader() error %v, want %v", err, context.Canceled)
+		}
+
+		// canceled while loading.
+		loadCtx, cancel := context.WithCancel(context.Background())
+		l, err := envload.NewLoader(lookup, append(opts, envload.WithContext(loadCtx))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		_, err = l.Value(&envload.Var{Name: "TOKEN", Field: "Token"}, false)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Value() error %v, want %v", err, context.Canceled)
+		}
+	})
+}
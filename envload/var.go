@@ -0,0 +1,354 @@
+package envload
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Var describes an environment variable populating a struct field.
+type Var struct {
+	// Name is the name in the env tag.
+	Name string `json:"name"`
+	// Field is the dotted path of the field, eg Address.Street.
+	Field string `json:"field"`
+	// Type is the kind of the field, eg string, int or float64, or duration, url and ip for
+	// time.Duration, url.URL and net.IP. Pointers are described by the type they point to.
+	Type            string            `json:"type"`
+	Aliases         []string          `json:"aliases,omitempty"`
+	Deprecated      []string          `json:"deprecated,omitempty"`
+	Default         *string           `json:"default,omitempty"`
+	ProfileDefaults map[string]string `json:"profileDefaults,omitempty"`
+	Required        bool              `json:"required,omitempty"`
+	NotEmpty        bool              `json:"notEmpty,omitempty"`
+	Unset           bool              `json:"unset,omitempty"`
+	NoOverwrite     bool              `json:"noOverwrite,omitempty"`
+	Secret          bool              `json:"secret,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	Enum            []string          `json:"enum,omitempty"`
+	Min             string            `json:"min,omitempty"`
+	Max             string            `json:"max,omitempty"`
+}
+
+type parseFunc func(string) (interface{}, error)
+
+// parsers parse the values of the types named as in Var.Type. Integers are parsed into int64 and
+// floats into float64, whatever their size.
+var parsers = map[string]parseFunc{
+	"string": func(s string) (interface{}, error) {
+		return s, nil
+	},
+	"bool": func(s string) (interface{}, error) {
+		return strconv.ParseBool(s)
+	},
+	"float32": func(s string) (interface{}, error) {
+		return strconv.ParseFloat(s, 32)
+	},
+	"float64": func(s string) (interface{}, error) {
+		return strconv.ParseFloat(s, 64)
+	},
+	"int": func(s string) (interface{}, error) {
+		return strconv.ParseInt(s, 0, 0)
+	},
+	"int8": func(s string) (interface{}, error) {
+		return strconv.ParseInt(s, 0, 8)
+	},
+	"int32": func(s string) (interface{}, error) {
+		return strconv.ParseInt(s, 0, 32)
+	},
+	"int64": func(s string) (interface{}, error) {
+		return strconv.ParseInt(s, 0, 64)
+	},
+	"duration": func(s string) (interface{}, error) {
+		return time.ParseDuration(s)
+	},
+	"url": func(s string) (interface{}, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	},
+	"ip": func(s string) (interface{}, error) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %s", s)
+		}
+		return ip, nil
+	},
+}
+
+// Parser returns the function parsing values of the type typ, named as in Var.Type, and false
+// when there is none. Integers are parsed into int64, floats into float64, durations into
+// time.Duration, urls into url.URL and ips into net.IP.
+func Parser(typ string) (func(string) (interface{}, error), bool) {
+	p, ok := parsers[typ]
+	return p, ok
+}
+
+// Names returns the environment variable names of v in the order they are looked up: the name
+// followed by the aliases.
+func (v Var) Names() []string {
+	if v.Name == "" {
+		return nil
+	}
+	return append([]string{v.Name}, v.Aliases...)
+}
+
+// WithPrefix returns v with the envPrefix of the enclosing structs prepended to its names.
+func (v Var) WithPrefix(prefix string) Var {
+	if prefix == "" || v.Name == "" {
+		return v
+	}
+
+	v.Name = prefix + v.Name
+	v.Aliases = prefixNames(v.Aliases, prefix)
+	v.Deprecated = prefixNames(v.Deprecated, prefix)
+	return v
+}
+
+func prefixNames(names []string, prefix string) []string {
+	if names == nil {
+		return nil
+	}
+	pn := make([]string, 0, len(names))
+	for _, n := range names {
+		pn = append(pn, prefix+n)
+	}
+	return pn
+}
+
+// DefaultFor returns the default for the given profile, falling back to the plain default.
+// The returned profile is empty when the plain default is used.
+func (v Var) DefaultFor(profile string) (string, string, bool) {
+	if d, ok := v.ProfileDefaults[profile]; ok && profile != "" {
+		return d, profile, true
+	}
+	if v.Default == nil {
+		return "", "", false
+	}
+	return *v.Default, "", true
+}
+
+// Resolve returns the raw value of v in the variables looked up with l, following the same rules
+// as the Loader, and where it came from. preset tells whether the field holds a non-zero value.
+// Neither secret references nor encrypted values are resolved.
+func (v Var) Resolve(l Lookuper, profile string, preset bool) (string, FieldReport, error) {
+	return v.resolve(v.Field, l, profile, preset)
+}
+
+// resolve picks the raw value of a field, named field in errors. The order of precedence is:
+//   - the first of the field's env variables that is set
+//   - the preset value of the field, if non-zero and the noOverwrite option is used
+//   - the profile specific envDefault, then the plain envDefault
+//
+// preset tells whether the field holds a non-zero value set before loading. The returned report has
+// Source SourcePreset or SourceNone when the field is to be left as it is.
+func (v Var) resolve(field string, l Lookuper, profile string, preset bool) (string, FieldReport, error) {
+	fr := FieldReport{Field: field, Secret: v.Secret}
+
+	name, val, ok := v.lookup(l)
+	switch {
+	case ok:
+		if v.NotEmpty && val == "" {
+			return "", fr, fmt.Errorf("%s requires environment variable %s not to be empty", field, name)
+		}
+		fr.Var, fr.Source, fr.Deprecated = name, SourceEnv, v.deprecated(name)
+	case v.NoOverwrite && preset:
+		fr.Source = SourcePreset
+	default:
+		val, fr.Profile, ok = v.DefaultFor(profile)
+		if !ok {
+			if v.Required {
+				return "", fr, fmt.Errorf("%s requires environment variable %s to be set",
+					field, strings.Join(v.Names(), " or "))
+			}
+			fr.Source = SourceNone
+			return "", fr, nil
+		}
+		if v.NotEmpty && val == "" {
+			return "", fr, fmt.Errorf("%s requires a non-empty default", field)
+		}
+		fr.Source = SourceDefault
+	}
+	return val, fr, nil
+}
+
+// lookup returns the value of the first of the variable's names that is set together with
+// the name it was found under.
+func (v Var) lookup(l Lookuper) (string, string, bool) {
+	if v.Name == "" {
+		return "", "", false
+	}
+	if val, ok := l.LookupEnv(v.Name); ok {
+		return v.Name, val, true
+	}
+	for _, n := range v.Aliases {
+		if val, ok := l.LookupEnv(n); ok {
+			return n, val, true
+		}
+	}
+	return "", "", false
+}
+
+func (v Var) deprecated(name string) bool {
+	for _, n := range v.Deprecated {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// logString describes the variable for logs. Defaults are left out as they may be secrets.
+func (v Var) logString() string {
+	return fmt.Sprintf("env %s, aliases %v, required %t, notEmpty %t, unset %t, noOverwrite %t, secret %t, default %t",
+		v.Name, v.Aliases, v.Required, v.NotEmpty, v.Unset, v.NoOverwrite, v.Secret, v.Default != nil)
+}
+
+// Check validates that val can be parsed into the type of the variable and satisfies its
+// constraints. Encrypted values and references to secrets of the schemes registered with
+// RegisterSecretProvider cannot be resolved without the keys and providers, so they are not checked.
+func (v Var) Check(val string) error {
+	if strings.HasPrefix(val, EncryptedPrefix) || registeredSecret(val) {
+		return nil
+	}
+
+	parseF, ok := parsers[v.Type]
+	if !ok {
+		return fmt.Errorf("%s: no parser found for type %s", v.Field, v.Type)
+	}
+	_, err := parseF(val)
+	if err != nil {
+		return fmt.Errorf("%s: invalid value %s for %s", v.Field, mask(val, v.Secret), v.Type)
+	}
+	return v.validate(v.Field, val, v.Secret)
+}
+
+// validate checks val against the envEnum, envMin and envMax constraints of the field. Values and
+// constraints are compared once parsed into the type of the field, so that 0x1f90 is 8080. Minimum
+// and maximum apply to numbers and durations. A value that cannot be parsed is left to be reported
+// when it is set.
+func (v Var) validate(field, val string, secret bool) error {
+	if len(v.Enum) == 0 && v.Min == "" && v.Max == "" {
+		return nil
+	}
+	parseF, ok := parsers[v.Type]
+	if !ok {
+		parseF = parsers["string"]
+	}
+	pv, err := parseF(val)
+	if err != nil {
+		return nil
+	}
+
+	if len(v.Enum) > 0 && !v.inEnum(val, pv, parseF) {
+		return fmt.Errorf("%s: value %s is not one of %s", field, mask(val, secret), strings.Join(v.Enum, ", "))
+	}
+	if v.Min == "" && v.Max == "" {
+		return nil
+	}
+
+	if _, ok := compare(pv, pv); !ok {
+		return fmt.Errorf("%s: value %s is not a number", field, mask(val, secret))
+	}
+	if v.Min != "" {
+		c, err := bound(pv, v.Min, field, "envMin", parseF)
+		if err != nil {
+			return err
+		}
+		if c < 0 {
+			return fmt.Errorf("%s: value %s is less than %s", field, mask(val, secret), v.Min)
+		}
+	}
+	if v.Max != "" {
+		c, err := bound(pv, v.Max, field, "envMax", parseF)
+		if err != nil {
+			return err
+		}
+		if c > 0 {
+			return fmt.Errorf("%s: value %s is greater than %s", field, mask(val, secret), v.Max)
+		}
+	}
+	return nil
+}
+
+// inEnum tells whether the value val, parsed into pv, is one of the enum values once parsed, or
+// as written for those that do not parse.
+func (v Var) inEnum(val string, pv interface{}, parseF parseFunc) bool {
+	for _, e := range v.Enum {
+		ev, err := parseF(e)
+		if (err == nil && equal(pv, ev)) || (err != nil && val == e) {
+			return true
+		}
+	}
+	return false
+}
+
+// bound compares the parsed value pv with the bound b, named name in errors.
+func bound(pv interface{}, b, field, name string, parseF parseFunc) (int, error) {
+	bv, err := parseF(b)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid %s %s", field, name, b)
+	}
+	c, ok := compare(pv, bv)
+	if !ok {
+		return 0, fmt.Errorf("%s: invalid %s %s", field, name, b)
+	}
+	return c, nil
+}
+
+// equal tells whether parsed values are the same.
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case net.IP:
+		bv, ok := b.(net.IP)
+		return ok && av.Equal(bv)
+	case url.URL:
+		bv, ok := b.(url.URL)
+		return ok && av.String() == bv.String()
+	}
+	return a == b
+}
+
+// compare compares parsed numbers and durations, returning -1, 0 or 1. It returns false for
+// other values.
+func compare(a, b interface{}) (int, bool) {
+	if d, ok := a.(time.Duration); ok {
+		a = int64(d)
+	}
+	if d, ok := b.(time.Duration); ok {
+		b = int64(d)
+	}
+
+	switch av := a.(type) {
+	case int64:
+		bv, ok := b.(int64)
+		return order(av < bv, av > bv), ok
+	case float64:
+		bv, ok := b.(float64)
+		return order(av < bv, av > bv), ok
+	}
+	return 0, false
+}
+
+func order(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// mask hides secret values from logs and error messages.
+func mask(val string, secret bool) string {
+	if secret {
+		return "******"
+	}
+	return val
+}
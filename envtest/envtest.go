@@ -0,0 +1,251 @@
+// Package envtest helps testing code configured with package env without leaking environment
+// variables between tests.
+//
+//	func TestServer(t *testing.T) {
+//		l := envtest.NewLookuper(map[string]string{"DB_HOST": "db"})
+//
+//		var cfg Config
+//		err := env.Parse(&cfg, env.WithLookuper(l))
+//		...
+//		envtest.AssertConfig(t, Config{DB: DB{Host: "db", Port: 5432}}, cfg)
+//	}
+package envtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+)
+
+var update = flag.Bool("envtest.update", false, "update the golden files of envtest.AssertDescribe")
+
+// Setenv sets the environment variables for the duration of the test. The whole environment is
+// snapshotted and restored when the test and its subtests complete, so variables set or unset
+// by the code under test, eg with the unset option, do not leak either.
+//
+// As the process environment is shared, Setenv must not be used in parallel tests. A Lookuper
+// does not have this limitation.
+func Setenv(t testing.TB, vars map[string]string) {
+	t.Helper()
+
+	snapshot := os.Environ()
+	t.Cleanup(func() {
+		os.Clearenv()
+		for _, kv := range snapshot {
+			p := strings.SplitN(kv, "=", 2)
+			os.Setenv(p[0], p[1])
+		}
+	})
+
+	for k, v := range vars {
+		err := os.Setenv(k, v)
+		if err != nil {
+			t.Fatalf("envtest: failed to set %s: %v", k, err)
+		}
+	}
+}
+
+// Lookuper is a fake env.Lookuper serving variables from a map. It records the names looked up
+// and is safe for concurrent use.
+type Lookuper struct {
+	mu      sync.Mutex
+	vars    map[string]string
+	lookups []string
+}
+
+var _ env.Lookuper = (*Lookuper)(nil)
+
+// NewLookuper returns a Lookuper serving a copy of vars.
+func NewLookuper(vars map[string]string) *Lookuper {
+	l := &Lookuper{vars: make(map[string]string, len(vars))}
+	for k, v := range vars {
+		l.vars[k] = v
+	}
+	return l
+}
+
+// LookupEnv implements env.Lookuper.
+func (l *Lookuper) LookupEnv(name string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lookups = append(l.lookups, name)
+	v, ok := l.vars[name]
+	return v, ok
+}
+
+// Set sets a variable.
+func (l *Lookuper) Set(name, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.vars[name] = value
+}
+
+// Unset removes a variable.
+func (l *Lookuper) Unset(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.vars, name)
+}
+
+// Lookups returns the names looked up so far, in order.
+func (l *Lookuper) Lookups() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.lookups...)
+}
+
+// AssertConfig reports, one error per field, how got differs from want. Nested structs and
+// pointers are compared field by field, other values, eg slices, as a whole.
+func AssertConfig(t testing.TB, want, got interface{}) {
+	t.Helper()
+
+	wv, gv := reflect.ValueOf(want), reflect.ValueOf(got)
+	if !wv.IsValid() || !gv.IsValid() {
+		if wv.IsValid() != gv.IsValid() {
+			t.Errorf("config: want %v, got %v", want, got)
+		}
+		return
+	}
+	if wv.Type() != gv.Type() {
+		t.Errorf("config type: want %s, got %s", wv.Type(), gv.Type())
+		return
+	}
+	for _, d := range diff(wv, gv, "") {
+		t.Error(d)
+	}
+}
+
+// diff returns the differences between two values of the same type, described by their field path.
+func diff(want, got reflect.Value, path string) []string {
+	name := path
+	if name == "" {
+		name = "config"
+	}
+
+	switch want.Kind() {
+	case reflect.Ptr:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				return []string{fmt.Sprintf("%s: want %s, got %s", name, format(want), format(got))}
+			}
+			return nil
+		}
+		return diff(want.Elem(), got.Elem(), path)
+	case reflect.Struct:
+		var dd []string
+		for i := 0; i < want.NumField(); i++ {
+			sf := want.Type().Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			p := sf.Name
+			if path != "" {
+				p = path + "." + sf.Name
+			}
+			dd = append(dd, diff(want.Field(i), got.Field(i), p)...)
+		}
+		return dd
+	}
+
+	if !want.CanInterface() || !got.CanInterface() {
+		return nil
+	}
+	if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+		return []string{fmt.Sprintf("%s: want %s, got %s", name, format(want), format(got))}
+	}
+	return nil
+}
+
+func format(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return "nil"
+	}
+	if v.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v.String())
+	}
+	if !v.CanInterface() {
+		return v.Type().String()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// AssertDescribe compares the JSON encoded env.Describe schema of the struct v points to with
+// the golden file, eg testdata/config.golden.json. Run the tests with -envtest.update to write
+// the golden file instead.
+func AssertDescribe(t testing.TB, v interface{}, golden string) {
+	t.Helper()
+
+	s, err := env.Describe(v)
+	if err != nil {
+		t.Fatalf("envtest: %v", err)
+	}
+	got, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		t.Fatalf("envtest: %v", err)
+	}
+	got = append(got, '\n')
+
+	AssertGolden(t, got, golden)
+}
+
+// AssertGolden compares got with the content of the golden file. Run the tests with
+// -envtest.update to write the golden file instead.
+func AssertGolden(t testing.TB, got []byte, golden string) {
+	t.Helper()
+
+	if *update {
+		err := os.MkdirAll(filepath.Dir(golden), 0o755)
+		if err == nil {
+			err = os.WriteFile(golden, got, 0o644) //nolint:gosec // golden files are not secret.
+		}
+		if err != nil {
+			t.Fatalf("envtest: failed to update %s: %v", golden, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("envtest: %v (run with -envtest.update to create it)", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("%s differs:\n%s", golden, lineDiff(string(want), string(got)))
+	}
+}
+
+// lineDiff lists, in order, the lines only in want, prefixed -, then the lines only in got,
+// prefixed +.
+func lineDiff(want, got string) string {
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	missing := func(lines, other []string, sign string) []string {
+		left := map[string]int{}
+		for _, l := range other {
+			left[l]++
+		}
+		var dd []string
+		for _, l := range lines {
+			if left[l] > 0 {
+				left[l]--
+				continue
+			}
+			dd = append(dd, sign+" "+l)
+		}
+		return dd
+	}
+
+	dd := append(missing(wl, gl, "-"), missing(gl, wl, "+")...)
+	return strings.Join(dd, "\n")
+}
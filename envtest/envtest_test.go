@@ -0,0 +1,248 @@
+package envtest_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/env"
+	"github.com/tamarakaufler/go-and-reflect/envtest"
+)
+
+// recorder is a testing.TB recording the failures reported to it.
+type recorder struct {
+	testing.TB
+	errs   []string
+	fatals []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprint(args...))
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.fatals = append(r.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestSetenv(t *testing.T) {
+	t.Setenv("ENVTEST_KEPT", "kept")
+	t.Setenv("ENVTEST_REMOVED", "removed")
+
+	t.Run("set", func(t *testing.T) {
+		envtest.Setenv(t, map[string]string{"ENVTEST_SET": "set", "ENVTEST_KEPT": "changed"})
+		if v := os.Getenv("ENVTEST_SET"); v != "set" {
+			t.Errorf("ENVTEST_SET is %q, want set", v)
+		}
+		if v := os.Getenv("ENVTEST_KEPT"); v != "changed" {
+			t.Errorf("ENVTEST_KEPT is %q, want changed", v)
+		}
+		// changes made by the code under test are undone too.
+		os.Setenv("ENVTEST_ADDED", "added")
+		os.Unsetenv("ENVTEST_REMOVED")
+	})
+
+	tests := []struct {
+		name string
+		val  string
+		ok   bool
+	}{
+		{name: "ENVTEST_SET"},
+		{name: "ENVTEST_ADDED"},
+		{name: "ENVTEST_KEPT", val: "kept", ok: true},
+		{name: "ENVTEST_REMOVED", val: "removed", ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, ok := os.LookupEnv(tt.name)
+			if val != tt.val || ok != tt.ok {
+				t.Errorf("after the test %s is %q, %t, want %q, %t", tt.name, val, ok, tt.val, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLookuper(t *testing.T) {
+	vars := map[string]string{"HOST": "db", "PORT": "5432"}
+	l := envtest.NewLookuper(vars)
+	vars["HOST"] = "changed"
+
+	tests := []struct {
+		name   string
+		change func()
+		val    string
+		ok     bool
+	}{
+		{name: "HOST", val: "db", ok: true},
+		{name: "PORT", change: func() { l.Set("PORT", "6543") }, val: "6543", ok: true},
+		{name: "PORT", change: func() { l.Unset("PORT") }},
+		{name: "MISSING"},
+	}
+	for _, tt := range tests {
+		if tt.change != nil {
+			tt.change()
+		}
+		val, ok := l.LookupEnv(tt.name)
+		if val != tt.val || ok != tt.ok {
+			t.Errorf("LookupEnv(%s) = %q, %t, want %q, %t", tt.name, val, ok, tt.val, tt.ok)
+		}
+	}
+
+	want := []string{"HOST", "PORT", "PORT", "MISSING"}
+	if got := l.Lookups(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookups() = %v, want %v", got, want)
+	}
+}
+
+func TestLookuperParse(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST" envAliases:"DB_HOST"`
+	}
+
+	l := envtest.NewLookuper(map[string]string{"DB_HOST": "db"})
+	var c config
+	err := env.Parse(&c, env.WithLookuper(l), env.WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "db" {
+		t.Errorf("Host is %q, want db", c.Host)
+	}
+	want := []string{"HOST", "DB_HOST"}
+	if got := l.Lookups(); !reflect.DeepEqual(got[len(got)-2:], want) {
+		t.Errorf("Lookups() = %v, want it to end with %v", got, want)
+	}
+}
+
+func TestAssertConfig(t *testing.T) {
+	type db struct {
+		Host string
+		Port int
+	}
+	type config struct {
+		Name  string
+		DB    db
+		Cache *db
+		Tags  []string
+	}
+
+	tests := []struct {
+		name      string
+		want, got interface{}
+		errs      []string
+	}{
+		{
+			name: "equal",
+			want: config{Name: "a", Cache: &db{Port: 1}, Tags: []string{"x"}},
+			got:  config{Name: "a", Cache: &db{Port: 1}, Tags: []string{"x"}},
+		},
+		{
+			name: "fields",
+			want: config{Name: "a", DB: db{Host: "db", Port: 5432}, Tags: []string{"x"}},
+			got:  config{Name: "b", DB: db{Host: "db", Port: 6543}, Tags: []string{"y"}},
+			errs: []string{
+				`Name: want "a", got "b"`,
+				`DB.Port: want 5432, got 6543`,
+				`Tags: want [x], got [y]`,
+			},
+		},
+		{
+			name: "pointers",
+			want: config{Cache: &db{Host: "cache"}},
+			got:  config{},
+			errs: []string{`Cache: want &{cache 0}, got nil`},
+		},
+		{
+			name: "pointed to fields",
+			want: &config{Cache: &db{Host: "cache"}},
+			got:  &config{Cache: &db{Host: "redis"}},
+			errs: []string{`Cache.Host: want "cache", got "redis"`},
+		},
+		{
+			name: "types",
+			want: config{},
+			got:  db{},
+			errs: []string{`config type: want envtest_test.config, got envtest_test.db`},
+		},
+		{
+			name: "nil want",
+			got:  config{},
+			errs: []string{`config: want <nil>, got { { 0} <nil> []}`},
+		},
+		{
+			name: "nil both",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			envtest.AssertConfig(r, tt.want, tt.got)
+			if !reflect.DeepEqual(r.errs, tt.errs) {
+				t.Errorf("errors %q, want %q", r.errs, tt.errs)
+			}
+		})
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "testdata", "config.golden")
+
+	r := &recorder{}
+	envtest.AssertGolden(r, []byte("a\nb\n"), golden)
+	if len(r.fatals) != 1 || !strings.Contains(r.fatals[0], "-envtest.update") {
+		t.Errorf("missing golden file: fatal errors %q, want one suggesting -envtest.update", r.fatals)
+	}
+
+	setUpdate(t, true)
+	r = &recorder{}
+	envtest.AssertGolden(r, []byte("a\nb\n"), golden)
+	if len(r.errs)+len(r.fatals) > 0 {
+		t.Fatalf("update failed: %q %q", r.errs, r.fatals)
+	}
+	b, err := os.ReadFile(golden)
+	if err != nil || string(b) != "a\nb\n" {
+		t.Fatalf("golden file holds %q, %v, want the updated content", b, err)
+	}
+
+	setUpdate(t, false)
+	tests := []struct {
+		name string
+		got  string
+		errs []string
+	}{
+		{name: "same", got: "a\nb\n"},
+		{name: "different", got: "a\nc\n", errs: []string{golden + " differs:\n- b\n+ c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			envtest.AssertGolden(r, []byte(tt.got), golden)
+			if !reflect.DeepEqual(r.errs, tt.errs) {
+				t.Errorf("errors %q, want %q", r.errs, tt.errs)
+			}
+		})
+	}
+}
+
+// setUpdate sets the -envtest.update flag until the test completes.
+func setUpdate(t *testing.T, update bool) {
+	t.Helper()
+	f := flag.Lookup("envtest.update")
+	old := f.Value.String()
+	err := f.Value.Set(fmt.Sprint(update))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = f.Value.Set(old)
+	})
+}
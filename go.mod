@@ -1,5 +1,7 @@
 module github.com/tamarakaufler/go-and-reflect
 
-go 1.16
+go 1.18
 
 require github.com/mitchellh/mapstructure v1.4.0
+
+require golang.org/x/tools v0.17.0
@@ -0,0 +1,171 @@
+package reflect
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// CopyUnexported makes Clone deep copy unexported fields too, which requires package unsafe.
+// Without it, unexported fields are copied as an assignment does: pointers, slices and maps
+// are shared with the original. Unexported embedded structs are copied deep either way, but for
+// their own unexported fields.
+func CopyUnexported() Option {
+	return func(o *options) {
+		o.unexported = true
+	}
+}
+
+// Clone returns a deep copy of v: structs, pointers, interfaces, slices, arrays and maps are copied,
+// channels and functions shared. Pointers, slices and maps shared within v are shared within the copy,
+// and cycles are preserved, eg the copy of a doubly linked list is a doubly linked list.
+//
+// Struct fields tagged clone:"-" are left zero in the copy, fields tagged clone:"shallow" are copied
+// as an assignment does. Unexported fields are copied shallow, whatever their tag, unless
+// CopyUnexported is used. The exported fields of unexported embedded structs are copied deep.
+func Clone[T any](v T, opts ...Option) T {
+	c := &cloner{
+		ptrs:   map[visit]reflect.Value{},
+		maps:   map[visit]reflect.Value{},
+		slices: map[sliceVisit]reflect.Value{},
+	}
+	for _, opt := range opts {
+		opt(&c.options)
+	}
+
+	// set rather than asserted, a nil interface T does not convert back from interface{}.
+	var out T
+	reflect.ValueOf(&out).Elem().Set(c.clone(reflect.ValueOf(&v).Elem()))
+	return out
+}
+
+type (
+	cloner struct {
+		options
+		// the copies of the pointers, maps and slices already cloned.
+		ptrs   map[visit]reflect.Value
+		maps   map[visit]reflect.Value
+		slices map[sliceVisit]reflect.Value
+	}
+
+	sliceVisit struct {
+		visit
+		cap int
+	}
+)
+
+// clone returns a copy of v, of the same type.
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	t := v.Type()
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		k := visit{ptr: v.Pointer(), typ: t}
+		if p, ok := c.ptrs[k]; ok {
+			return p
+		}
+		p := reflect.New(t.Elem())
+		c.ptrs[k] = p // recorded before copying, a cycle leads back to the copy.
+		p.Elem().Set(c.clone(v.Elem()))
+		return p
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		i := reflect.New(t).Elem()
+		i.Set(c.clone(v.Elem()))
+		return i
+
+	case reflect.Struct:
+		return c.cloneStruct(v)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		k := sliceVisit{visit: visit{ptr: v.Pointer(), typ: t, len: v.Len()}, cap: v.Cap()}
+		if s, ok := c.slices[k]; ok {
+			return s
+		}
+		s := reflect.MakeSlice(t, v.Len(), v.Cap())
+		c.slices[k] = s
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return s
+
+	case reflect.Array:
+		a := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			a.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return a
+
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		k := visit{ptr: v.Pointer(), typ: t}
+		if m, ok := c.maps[k]; ok {
+			return m
+		}
+		m := reflect.MakeMapWithSize(t, v.Len())
+		c.maps[k] = m
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(c.clone(iter.Key()), c.clone(iter.Value()))
+		}
+		return m
+	}
+
+	// basic kinds are copied, channels, functions and unsafe pointers shared.
+	cp := reflect.New(t).Elem()
+	cp.Set(v)
+	return cp
+}
+
+// cloneStruct returns a copy of the struct v. The struct is first copied as a whole, which copies
+// unexported fields shallow, and the fields are then replaced by their deep copies.
+func (c *cloner) cloneStruct(v reflect.Value) reflect.Value {
+	t := v.Type()
+	if !v.CanAddr() {
+		// fields are made accessible through their addresses.
+		a := reflect.New(t).Elem()
+		a.Set(v)
+		v = a
+	}
+
+	s := reflect.New(t).Elem()
+	s.Set(v)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		f, sfv := s.Field(i), v.Field(i)
+
+		tag := sf.Tag.Get("clone")
+		if tag == "shallow" {
+			continue
+		}
+		if sf.PkgPath != "" {
+			// the exported fields of unexported embedded structs are promoted, they are copied
+			// deep as Diff and Equal compare them.
+			if !c.unexported && (tag == "-" || !embeddedStruct(sf)) {
+				continue
+			}
+			f, sfv = unexported(f), unexported(sfv)
+		}
+		if tag == "-" {
+			f.Set(reflect.Zero(sf.Type))
+			continue
+		}
+		f.Set(c.clone(sfv))
+	}
+	return s
+}
+
+// unexported returns the addressable field f as if it were exported.
+func unexported(f reflect.Value) reflect.Value {
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem() //nolint:gosec // a field of a struct held.
+}
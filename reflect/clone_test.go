@@ -0,0 +1,144 @@
+package reflect_test
+
+import (
+	"io"
+	"testing"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+func TestCloneNilInterface(t *testing.T) {
+	var r io.Reader
+	if got := refl.Clone(r); got != nil {
+		t.Errorf("Clone(nil io.Reader) = %v, want nil", got)
+	}
+	var e error
+	if got := refl.Clone(e); got != nil {
+		t.Errorf("Clone(nil error) = %v, want nil", got)
+	}
+	var v interface{}
+	if got := refl.Clone(v); got != nil {
+		t.Errorf("Clone(nil interface{}) = %v, want nil", got)
+	}
+
+	type holder struct {
+		R io.Reader
+	}
+	if got := refl.Clone(holder{}); got.R != nil {
+		t.Errorf("Clone(holder{}).R = %v, want nil", got.R)
+	}
+	if got := refl.Clone(&holder{}); got == nil || got.R != nil {
+		t.Errorf("Clone(&holder{}) = %+v, want a pointer to a zero holder", got)
+	}
+}
+
+type (
+	cloneNode struct {
+		Name       string
+		Prev, Next *cloneNode
+	}
+
+	cloneBase struct {
+		Tags []string
+		meta map[string]string
+	}
+
+	cloneDoc struct {
+		cloneBase
+		*cloneNode
+		Cache   []int `clone:"-"`
+		Shared  []int `clone:"shallow"`
+		scratch []int `clone:"-"`
+		secret  *string
+	}
+)
+
+func TestCloneCycles(t *testing.T) {
+	a := &cloneNode{Name: "a"}
+	b := &cloneNode{Name: "b", Prev: a}
+	a.Next, b.Next = b, a
+
+	c := refl.Clone(a)
+	if c == a || c.Next == b {
+		t.Fatal("the copy shares nodes with the original")
+	}
+	if c.Next.Prev != c || c.Next.Next != c {
+		t.Error("the copy lost the cycle")
+	}
+	if c.Name != "a" || c.Next.Name != "b" {
+		t.Errorf("names %q, %q, want a, b", c.Name, c.Next.Name)
+	}
+}
+
+func TestCloneSharedPointers(t *testing.T) {
+	type pair struct {
+		A, B *int
+		S, T []int
+	}
+	n := 1
+	s := []int{1, 2, 3}
+	p := pair{A: &n, B: &n, S: s, T: s}
+
+	c := refl.Clone(p)
+	if c.A == p.A {
+		t.Error("A is shared with the original")
+	}
+	if c.A != c.B {
+		t.Error("A and B are no longer shared within the copy")
+	}
+	c.S[0] = 10
+	if p.S[0] != 1 {
+		t.Error("S is shared with the original")
+	}
+	if c.T[0] != 10 {
+		t.Error("S and T are no longer shared within the copy")
+	}
+}
+
+func TestCloneTagsAndUnexported(t *testing.T) {
+	secret := "s"
+	d := cloneDoc{
+		cloneBase: cloneBase{Tags: []string{"a"}, meta: map[string]string{"k": "v"}},
+		cloneNode: &cloneNode{Name: "n"},
+		Cache:     []int{1},
+		Shared:    []int{2},
+		scratch:   []int{3},
+		secret:    &secret,
+	}
+
+	c := refl.Clone(d)
+	c.Tags[0] = "b"
+	if d.Tags[0] != "a" {
+		t.Error("the promoted field Tags is shared with the original")
+	}
+	c.Name = "m"
+	if d.Name != "n" {
+		t.Error("the embedded pointer is shared with the original")
+	}
+	if c.Cache != nil {
+		t.Errorf("Cache = %v, want it left zero", c.Cache)
+	}
+	if &c.Shared[0] != &d.Shared[0] {
+		t.Error("Shared is copied, want it shared")
+	}
+	// without CopyUnexported, unexported fields are copied shallow, tags aside.
+	if &c.scratch[0] != &d.scratch[0] || c.secret != d.secret {
+		t.Error("unexported fields are not shared with the original")
+	}
+	c.meta["k"] = "w"
+	if d.meta["k"] != "w" {
+		t.Error("the unexported field meta is copied, want it shared")
+	}
+
+	c = refl.Clone(d, refl.CopyUnexported())
+	if c.scratch != nil {
+		t.Errorf("scratch = %v, want it left zero", c.scratch)
+	}
+	if c.secret == d.secret || *c.secret != "s" {
+		t.Error("secret is shared with the original")
+	}
+	c.meta["k"] = "x"
+	if d.meta["k"] == "x" {
+		t.Error("meta is shared with the original")
+	}
+}
@@ -0,0 +1,425 @@
+package reflect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChangeKind tells how a value changed.
+type ChangeKind string
+
+const (
+	// Added means the value is only in the new data structure, eg a new map key.
+	Added ChangeKind = "added"
+	// Removed means the value is only in the old data structure.
+	Removed ChangeKind = "removed"
+	// Modified means the value differs.
+	Modified ChangeKind = "modified"
+)
+
+type (
+	// Change is a difference found by Diff.
+	Change struct {
+		// Path is the path of the value changed, eg Users[3].Address.City, see FieldInfo.Path. Elements
+		// of slices compared by key are addressed by their keys, eg Users["alice"].
+		Path string      `json:"path"`
+		Kind ChangeKind  `json:"kind"`
+		Old  interface{} `json:"old,omitempty"`
+		New  interface{} `json:"new,omitempty"`
+	}
+
+	// Option configures Diff, Clone and Equal. Options not applying to a function are ignored.
+	Option func(*options)
+
+	options struct {
+		ignore     []string
+		tolerance  float64
+		unexported bool
+
+		// Equal only.
+		ignoreTags     []string
+		nilEmpty       bool
+		unordered      bool
+		equalMethods   bool
+		skipUnexported bool
+	}
+)
+
+// IgnorePaths leaves the values at the paths, and what they hold, out of the comparison.
+// [] stands for any index or key, eg Users[].Password.
+func IgnorePaths(paths ...string) Option {
+	return func(o *options) {
+		o.ignore = append(o.ignore, paths...)
+	}
+}
+
+// FloatTolerance considers floats equal when they differ by at most tolerance.
+func FloatTolerance(tolerance float64) Option {
+	return func(o *options) {
+		o.tolerance = tolerance
+	}
+}
+
+// Diff returns the differences between the data structures a and b, of the same type, as changes
+// from a to b. Structs, pointers, interfaces, slices, arrays and maps are compared element by
+// element, time.Time values with Equal. Fields tagged diff:"-" and unexported fields are left out,
+// except for unexported embedded structs whose exported fields are compared, as encoding/json does.
+//
+// Slices are compared by index, unless their elements are structs, or pointers to structs, with
+// a field tagged diff:"key": elements are then matched by that key, as map elements are. Slices
+// holding a key twice are compared by index.
+func Diff(a, b interface{}, opts ...Option) []Change {
+	d := &differ{visited: map[visitPair]bool{}}
+	for _, opt := range opts {
+		opt(&d.options)
+	}
+
+	d.diff(reflect.ValueOf(a), reflect.ValueOf(b), "")
+	return d.changes
+}
+
+type (
+	differ struct {
+		options
+		changes []Change
+		// visited holds the pairs of pointers compared, which stops cycles.
+		visited map[visitPair]bool
+	}
+
+	visitPair struct {
+		a, b uintptr
+		typ  reflect.Type
+		len  int // of slices, which may share their arrays.
+	}
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (d *differ) diff(a, b reflect.Value, path string) {
+	if d.ignored(path) {
+		return
+	}
+
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return
+	case !a.IsValid():
+		d.add(path, Added, a, b)
+		return
+	case !b.IsValid():
+		d.add(path, Removed, a, b)
+		return
+	case a.Type() != b.Type():
+		d.add(path, Modified, a, b)
+		return
+	}
+
+	if a.Type() == timeType {
+		if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+			d.add(path, Modified, a, b)
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		d.diffPointer(a, b, path)
+	case reflect.Struct:
+		d.diffStruct(a, b, path)
+	case reflect.Slice, reflect.Array:
+		d.diffSlice(a, b, path)
+	case reflect.Map:
+		d.diffMap(a, b, path)
+	default:
+		d.diffLeaf(a, b, path)
+	}
+}
+
+// diffPointer compares what the pointers or interfaces point to. Pairs of pointers already
+// compared are skipped, which stops cycles.
+func (d *differ) diffPointer(a, b reflect.Value, path string) {
+	switch {
+	case a.IsNil() && b.IsNil():
+	case a.IsNil():
+		d.add(path, Added, reflect.Value{}, b)
+	case b.IsNil():
+		d.add(path, Removed, a, reflect.Value{})
+	default:
+		if a.Kind() == reflect.Ptr {
+			k := visitPair{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+			if d.visited[k] {
+				return
+			}
+			d.visited[k] = true
+		}
+		d.diff(a.Elem(), b.Elem(), path)
+	}
+}
+
+// diffStruct compares the fields of the structs.
+func (d *differ) diffStruct(a, b reflect.Value, path string) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Tag.Get("diff") == "-" {
+			continue
+		}
+		fa, fb := a.Field(i), b.Field(i)
+		if sf.PkgPath != "" {
+			// the exported fields of unexported embedded structs are promoted, as encoding/json
+			// does, the structs are made addressable to read them.
+			if !embeddedStruct(sf) || !a.CanInterface() || !b.CanInterface() {
+				continue
+			}
+			a, b = addressable(a), addressable(b)
+			fa, fb = unexported(a.Field(i)), unexported(b.Field(i))
+		}
+		d.diff(fa, fb, fieldPath(path, sf.Name))
+	}
+}
+
+// diffSlice compares the elements of the slices or arrays by key, when they have one, or by index.
+func (d *differ) diffSlice(a, b reflect.Value, path string) {
+	if key, ok := sliceKey(a.Type().Elem()); ok && d.diffKeyed(a, b, path, key) {
+		return
+	}
+	n := a.Len()
+	if b.Len() > n {
+		n = b.Len()
+	}
+	for i := 0; i < n; i++ {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= a.Len():
+			d.diff(reflect.Value{}, b.Index(i), p)
+		case i >= b.Len():
+			d.diff(a.Index(i), reflect.Value{}, p)
+		default:
+			d.diff(a.Index(i), b.Index(i), p)
+		}
+	}
+}
+
+// diffMap compares the elements of the maps, sorted by key.
+func (d *differ) diffMap(a, b reflect.Value, path string) {
+	keys := a.MapKeys()
+	for _, k := range b.MapKeys() {
+		if !a.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keyName(keys[i]) < keyName(keys[j])
+	})
+	for _, k := range keys {
+		d.diff(a.MapIndex(k), b.MapIndex(k), path+keyName(k))
+	}
+}
+
+// diffLeaf compares values holding no others, floats within the tolerance.
+func (d *differ) diffLeaf(a, b reflect.Value, path string) {
+	switch a.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if !d.floatEqual(a.Float(), b.Float()) {
+			d.add(path, Modified, a, b)
+		}
+		return
+	}
+
+	if !a.CanInterface() || !b.CanInterface() {
+		return
+	}
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		d.add(path, Modified, a, b)
+	}
+}
+
+// diffKeyed compares slices whose elements are matched by the key field at index key. It returns
+// false, comparing nothing, when a key is used twice within a slice: the elements cannot be matched
+// and the slices are compared by index instead.
+func (d *differ) diffKeyed(a, b reflect.Value, path string, key []int) bool {
+	type elem struct {
+		a, b reflect.Value
+	}
+	elems := map[string]*elem{}
+	var names []string
+	index := func(s reflect.Value, get func(e *elem) *reflect.Value) bool {
+		for i := 0; i < s.Len(); i++ {
+			v := s.Index(i)
+			name := elemKey(v, key, i)
+			e, ok := elems[name]
+			if !ok {
+				e = &elem{}
+				elems[name] = e
+				names = append(names, name)
+			}
+			if get(e).IsValid() {
+				return false
+			}
+			*get(e) = v
+		}
+		return true
+	}
+	if !index(a, func(e *elem) *reflect.Value { return &e.a }) ||
+		!index(b, func(e *elem) *reflect.Value { return &e.b }) {
+		return false
+	}
+
+	for _, name := range names {
+		e := elems[name]
+		d.diff(e.a, e.b, path+name)
+	}
+	return true
+}
+
+// sliceKey returns the index of the field tagged diff:"key" of the struct t, or t points to.
+func sliceKey(t reflect.Type) ([]int, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("diff") == "key" {
+			return []int{i}, true
+		}
+	}
+	return nil, false
+}
+
+// embeddedStruct tells whether the field is an embedded struct, or pointer to a struct.
+func embeddedStruct(sf reflect.StructField) bool {
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return sf.Anonymous && t.Kind() == reflect.Struct
+}
+
+// addressable returns v, or an addressable copy of it.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// elemKey returns the path segment of a slice element matched by key, eg ["alice"]. Nil elements
+// are known by their index.
+func elemKey(v reflect.Value, key []int, i int) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fmt.Sprintf("[%d]", i)
+		}
+		v = v.Elem()
+	}
+	return keyName(v.FieldByIndex(key))
+}
+
+// ignored tells whether the path matches one of the ignored paths.
+func (o *options) ignored(path string) bool {
+	if len(o.ignore) == 0 {
+		return false
+	}
+	for _, p := range o.ignore {
+		if p == path || (strings.Contains(p, "[]") && p == anyIndex(path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// floatEqual tells whether the floats differ by at most the tolerance. NaNs are never equal.
+func (o *options) floatEqual(x, y float64) bool {
+	return x == y || math.Abs(x-y) <= o.tolerance
+}
+
+// anyIndex replaces the indices and keys of the path with [], eg Users[3].Name with Users[].Name.
+func anyIndex(path string) string {
+	b := &strings.Builder{}
+	for len(path) > 0 {
+		if path[0] != '[' {
+			b.WriteByte(path[0])
+			path = path[1:]
+			continue
+		}
+		end := closingBracket(path)
+		if end < 0 {
+			b.WriteString(path)
+			break
+		}
+		b.WriteString("[]")
+		path = path[end+1:]
+	}
+	return b.String()
+}
+
+func (d *differ) add(path string, kind ChangeKind, a, b reflect.Value) {
+	d.changes = append(d.changes, Change{Path: path, Kind: kind, Old: valueOf(a), New: valueOf(b)})
+}
+
+func valueOf(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// String returns the change as a line of the text rendering, see WriteChanges.
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatChange(c.New))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, formatChange(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatChange(c.Old), formatChange(c.New))
+	}
+}
+
+func formatChange(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// WriteChanges writes the changes in a human readable form, one per line:
+//
+//	~ Address.City: "London" -> "Oxford"
+//	+ Meta["eu"]: {Lat:51.75 Lng:-0.33}
+//	- Users[2]: {Name:Lucien}
+func WriteChanges(w io.Writer, changes []Change) error {
+	for _, c := range changes {
+		_, err := fmt.Fprintln(w, c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteChangesJSON writes the changes as a JSON array of objects with path, kind, old and new.
+func WriteChangesJSON(w io.Writer, changes []Change) error {
+	if changes == nil {
+		changes = []Change{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(changes)
+}
@@ -0,0 +1,94 @@
+package reflect_test
+
+import (
+	"testing"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+type (
+	diffBase struct {
+		ID     int
+		hidden string
+	}
+
+	diffMeta struct {
+		Owner string
+	}
+
+	diffDoc struct {
+		diffBase
+		*diffMeta
+		Title string
+	}
+
+	diffUser struct {
+		Name string `diff:"key"`
+		Age  int
+	}
+)
+
+func TestDiffUnexportedEmbedded(t *testing.T) {
+	a := diffDoc{diffBase: diffBase{ID: 1, hidden: "a"}, diffMeta: &diffMeta{Owner: "alice"}, Title: "x"}
+	b := diffDoc{diffBase: diffBase{ID: 2, hidden: "b"}, diffMeta: &diffMeta{Owner: "bob"}, Title: "x"}
+
+	for name, changes := range map[string][]refl.Change{
+		"values":   refl.Diff(a, b),
+		"pointers": refl.Diff(&a, &b),
+	} {
+		t.Run(name, func(t *testing.T) {
+			want := []refl.Change{
+				{Path: "diffBase.ID", Kind: refl.Modified, Old: 1, New: 2},
+				{Path: "diffMeta.Owner", Kind: refl.Modified, Old: "alice", New: "bob"},
+			}
+			wantChanges(t, changes, want)
+		})
+	}
+
+	changes := refl.Diff(diffDoc{}, diffDoc{diffMeta: &diffMeta{}})
+	wantChanges(t, changes, []refl.Change{{Path: "diffMeta", Kind: refl.Added, New: &diffMeta{}}})
+}
+
+func TestDiffDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []diffUser
+		want []refl.Change
+	}{
+		{
+			name: "unique keys",
+			a:    []diffUser{{Name: "alice", Age: 30}, {Name: "bob", Age: 40}},
+			b:    []diffUser{{Name: "bob", Age: 41}, {Name: "alice", Age: 30}},
+			want: []refl.Change{{Path: `["bob"].Age`, Kind: refl.Modified, Old: 40, New: 41}},
+		},
+		{
+			name: "duplicate key compared by index",
+			a:    []diffUser{{Name: "alice", Age: 30}, {Name: "alice", Age: 31}},
+			b:    []diffUser{{Name: "alice", Age: 30}, {Name: "alice", Age: 32}},
+			want: []refl.Change{{Path: "[1].Age", Kind: refl.Modified, Old: 31, New: 32}},
+		},
+		{
+			name: "duplicate key in the new slice",
+			a:    []diffUser{{Name: "alice", Age: 30}},
+			b:    []diffUser{{Name: "alice", Age: 30}, {Name: "alice", Age: 31}},
+			want: []refl.Change{{Path: "[1]", Kind: refl.Added, New: diffUser{Name: "alice", Age: 31}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantChanges(t, refl.Diff(tt.a, tt.b), tt.want)
+		})
+	}
+}
+
+func wantChanges(t *testing.T, got, want []refl.Change) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d changes %+v, want %+v", len(got), got, want)
+	}
+	for i := range want {
+		if ok, why := refl.Equal(got[i], want[i]); !ok {
+			t.Errorf("change %d = %+v, want %+v: %s", i, got[i], want[i], why)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package reflect
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// IgnoreTag leaves fields with a tag of the key, eg secret, out of the comparison.
+// Fields tagged equal:"-" are always left out.
+func IgnoreTag(keys ...string) Option {
+	return func(o *options) {
+		o.ignoreTags = append(o.ignoreTags, keys...)
+	}
+}
+
+// NilEqualsEmpty considers nil slices and maps equal to empty ones, eg a nil []rune to []rune{}.
+func NilEqualsEmpty() Option {
+	return func(o *options) {
+		o.nilEmpty = true
+	}
+}
+
+// Unordered compares slices and arrays regardless of the order of their elements.
+func Unordered() Option {
+	return func(o *options) {
+		o.unordered = true
+	}
+}
+
+// EqualMethods compares values with their Equal method, eg time.Time, when their type has one
+// of the form func (T) Equal(T) bool.
+func EqualMethods() Option {
+	return func(o *options) {
+		o.equalMethods = true
+	}
+}
+
+// SkipUnexported leaves unexported fields out of the comparison. Unexported embedded structs are
+// still compared, their exported fields being promoted, as encoding/json does.
+func SkipUnexported() Option {
+	return func(o *options) {
+		o.skipUnexported = true
+	}
+}
+
+// Equal tells whether the data structures a and b are deeply equal, as reflect.DeepEqual does, and
+// when they are not returns the path of the first value differing, eg Address.Street[2], "" when a
+// and b differ themselves. The comparison is configured by IgnorePaths, IgnoreTag, FloatTolerance,
+// NilEqualsEmpty, Unordered, EqualMethods and SkipUnexported.
+func Equal(a, b interface{}, opts ...Option) (bool, string) {
+	e := &equaler{visited: map[visitPair]bool{}}
+	for _, opt := range opts {
+		opt(&e.options)
+	}
+
+	path, ok := e.equal(reflect.ValueOf(a), reflect.ValueOf(b), "")
+	if ok {
+		return true, ""
+	}
+	return false, path
+}
+
+type equaler struct {
+	options
+	// visited holds the pairs of pointers, slices and maps being compared, which stops cycles.
+	visited map[visitPair]bool
+}
+
+// equal compares a and b and returns the path where they first differ.
+func (e *equaler) equal(a, b reflect.Value, path string) (string, bool) {
+	if e.ignored(path) {
+		return "", true
+	}
+
+	switch {
+	case !a.IsValid() || !b.IsValid():
+		return path, a.IsValid() == b.IsValid()
+	case a.Type() != b.Type():
+		return path, false
+	}
+
+	if e.equalMethods {
+		if eq, ok := equalMethod(a, b); ok {
+			return path, eq
+		}
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return path, a.IsNil() == b.IsNil()
+		}
+		if a.Kind() == reflect.Ptr && e.seen(a, b) {
+			return "", true
+		}
+		return e.equal(a.Elem(), b.Elem(), path)
+
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if e.skipped(sf) {
+				continue
+			}
+			if p, ok := e.equal(a.Field(i), b.Field(i), fieldPath(path, sf.Name)); !ok {
+				return p, false
+			}
+		}
+		return "", true
+
+	case reflect.Slice, reflect.Map:
+		switch {
+		case e.nilEmpty && a.Len() == 0 && b.Len() == 0:
+			return "", true
+		case a.IsNil() != b.IsNil() || a.Len() != b.Len():
+			return path, false
+		case e.seen(a, b):
+			return "", true
+		}
+		if a.Kind() == reflect.Map {
+			return e.equalMaps(a, b, path)
+		}
+		return e.equalElems(a, b, path)
+
+	case reflect.Array:
+		return e.equalElems(a, b, path)
+
+	case reflect.Float32, reflect.Float64:
+		return path, e.floatEqual(a.Float(), b.Float())
+
+	case reflect.Complex64, reflect.Complex128:
+		x, y := a.Complex(), b.Complex()
+		return path, e.floatEqual(real(x), real(y)) && e.floatEqual(imag(x), imag(y))
+
+	case reflect.Bool:
+		return path, a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return path, a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return path, a.Uint() == b.Uint()
+	case reflect.String:
+		return path, a.String() == b.String()
+	case reflect.Func:
+		// as reflect.DeepEqual, functions are only equal when both are nil.
+		return path, a.IsNil() && b.IsNil()
+	}
+
+	// channels and unsafe pointers.
+	return path, a.Pointer() == b.Pointer()
+}
+
+// equalElems compares the elements of the slices or arrays a and b, of the same length, in order
+// or, with Unordered, in any order.
+func (e *equaler) equalElems(a, b reflect.Value, path string) (string, bool) {
+	if !e.unordered {
+		for i := 0; i < a.Len(); i++ {
+			if p, ok := e.equal(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); !ok {
+				return p, false
+			}
+		}
+		return "", true
+	}
+
+	// each element of a is matched with the first element of b equal to it and not matched yet.
+	matched := make([]bool, b.Len())
+	for i := 0; i < a.Len(); i++ {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		found := false
+		for j := 0; j < b.Len() && !found; j++ {
+			if matched[j] {
+				continue
+			}
+			// a failed attempt must not leave pairs marked as visited.
+			trial := &equaler{options: e.options, visited: map[visitPair]bool{}}
+			if _, ok := trial.equal(a.Index(i), b.Index(j), p); ok {
+				matched[j], found = true, true
+			}
+		}
+		if !found {
+			return p, false
+		}
+	}
+	return "", true
+}
+
+// equalMaps compares the maps a and b, of the same length, key by key in the order of the keys.
+func (e *equaler) equalMaps(a, b reflect.Value, path string) (string, bool) {
+	keys := a.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keyName(keys[i]) < keyName(keys[j])
+	})
+	for _, k := range keys {
+		p := path + keyName(k)
+		bv := b.MapIndex(k)
+		if !bv.IsValid() {
+			return p, false
+		}
+		if p, ok := e.equal(a.MapIndex(k), bv, p); !ok {
+			return p, false
+		}
+	}
+	return "", true
+}
+
+// seen tells whether the pair of pointers, slices or maps is already being compared, and marks it.
+func (e *equaler) seen(a, b reflect.Value) bool {
+	k := visitPair{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+	if a.Kind() == reflect.Slice {
+		k.len = a.Len()
+	}
+	if e.visited[k] {
+		return true
+	}
+	e.visited[k] = true
+	return false
+}
+
+// skipped tells whether the struct field is left out of the comparison.
+func (e *equaler) skipped(sf reflect.StructField) bool {
+	if sf.Tag.Get("equal") == "-" || (e.skipUnexported && sf.PkgPath != "" && !embeddedStruct(sf)) {
+		return true
+	}
+	for _, key := range e.ignoreTags {
+		if _, ok := sf.Tag.Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// equalMethod compares a and b with their Equal method. It returns false as second value when
+// there is no method of the form func (T) Equal(T) bool to call.
+func equalMethod(a, b reflect.Value) (bool, bool) {
+	if !a.CanInterface() || !b.CanInterface() {
+		return false, false
+	}
+	m := a.MethodByName("Equal")
+	if !m.IsValid() {
+		return false, false
+	}
+	mt := m.Type()
+	if mt.NumIn() != 1 || mt.In(0) != a.Type() || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool {
+		return false, false
+	}
+	if (a.Kind() == reflect.Ptr || a.Kind() == reflect.Interface) && (a.IsNil() || b.IsNil()) {
+		return a.IsNil() == b.IsNil(), true
+	}
+	return m.Call([]reflect.Value{b})[0].Bool(), true
+}
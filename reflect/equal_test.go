@@ -0,0 +1,57 @@
+package reflect_test
+
+import (
+	"testing"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+func TestEqualSkipUnexportedEmbedded(t *testing.T) {
+	type base struct {
+		ID     int
+		hidden string
+	}
+	type meta struct {
+		Owner string
+	}
+	type doc struct {
+		base
+		*meta
+		Title string
+		note  string
+	}
+
+	tests := []struct {
+		name string
+		a, b doc
+		ok   bool
+		path string
+	}{
+		{
+			name: "unexported fields differ",
+			a:    doc{base: base{ID: 1, hidden: "a"}, note: "a"},
+			b:    doc{base: base{ID: 1, hidden: "b"}, note: "b"},
+			ok:   true,
+		},
+		{
+			name: "promoted field differs",
+			a:    doc{base: base{ID: 1}},
+			b:    doc{base: base{ID: 2}},
+			path: "base.ID",
+		},
+		{
+			name: "promoted field through a pointer differs",
+			a:    doc{meta: &meta{Owner: "alice"}},
+			b:    doc{meta: &meta{Owner: "bob"}},
+			path: "meta.Owner",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, path := refl.Equal(tt.a, tt.b, refl.SkipUnexported())
+			if ok != tt.ok || path != tt.path {
+				t.Errorf("Equal = %v, %q, want %v, %q", ok, path, tt.ok, tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package reflect
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+type (
+	// StructLayout is the memory layout of a struct: where its fields are and the padding between
+	// them, with an order of the fields that wastes the least memory.
+	StructLayout struct {
+		// Name is the name of the struct type, eg User.
+		Name  string
+		Size  uintptr
+		Align uintptr
+		// Fields are the fields in the order they are declared.
+		Fields []FieldLayout
+		// Wasted is the number of padding bytes, the sum of the padding of the fields.
+		Wasted uintptr
+
+		// Suggested are the fields in the order taking the least memory, by alignment, largest
+		// first. It is the declared order when that cannot be improved.
+		Suggested     []FieldLayout
+		SuggestedSize uintptr
+	}
+
+	// FieldLayout is where a field is in its struct.
+	FieldLayout struct {
+		Name string
+		// Type is the type of the field as written in Go, eg []string.
+		Type   string
+		Offset uintptr
+		Size   uintptr
+		Align  uintptr
+		// Padding is the number of bytes following the field before the next one, or the end
+		// of the struct.
+		Padding uintptr
+	}
+)
+
+// Layout returns the memory layout of the struct type t, or of the struct t points to, on the
+// architecture the program runs on.
+func Layout(t reflect.Type) (*StructLayout, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %s must be a struct", t)
+	}
+
+	fields := make([]FieldLayout, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fields = append(fields, FieldLayout{
+			Name:   sf.Name,
+			Type:   sf.Type.String(),
+			Offset: sf.Offset,
+			Size:   sf.Type.Size(),
+			Align:  uintptr(sf.Type.Align()),
+		})
+	}
+
+	name := t.Name()
+	if name == "" {
+		name = t.String()
+	}
+	return NewStructLayout(name, t.Size(), uintptr(t.Align()), fields), nil
+}
+
+// NewStructLayout returns the layout of a struct of the given size and alignment from the offsets,
+// sizes and alignments of its fields, the padding and the suggested order worked out. It describes
+// structs known from their source, eg through go/types.Sizes, as Layout does from a reflect.Type.
+func NewStructLayout(name string, size, align uintptr, fields []FieldLayout) *StructLayout {
+	l := &StructLayout{
+		Name:   name,
+		Size:   size,
+		Align:  align,
+		Fields: fields,
+	}
+	l.Wasted = pad(l.Fields, size)
+
+	suggested := make([]FieldLayout, len(fields))
+	copy(suggested, fields)
+	// zero sized fields go first, a zero sized field at the end is padded so that its address
+	// does not point past the struct.
+	sort.SliceStable(suggested, func(i, j int) bool {
+		zi, zj := suggested[i].Size == 0, suggested[j].Size == 0
+		if zi != zj {
+			return zi
+		}
+		return suggested[i].Align > suggested[j].Align
+	})
+	var off uintptr
+	for i := range suggested {
+		off = alignUp(off, suggested[i].Align)
+		suggested[i].Offset = off
+		off += suggested[i].Size
+	}
+	l.SuggestedSize = alignUp(off, align)
+	if l.SuggestedSize >= size {
+		// the declared order is as good.
+		suggested, l.SuggestedSize = fields, size
+	} else {
+		pad(suggested, l.SuggestedSize)
+	}
+	l.Suggested = suggested
+	return l
+}
+
+// pad sets the padding of the fields, ordered by offset, in a struct of the size and returns
+// their sum.
+func pad(fields []FieldLayout, size uintptr) uintptr {
+	var wasted uintptr
+	for i := range fields {
+		end := size
+		if i+1 < len(fields) {
+			end = fields[i+1].Offset
+		}
+		fields[i].Padding = end - fields[i].Offset - fields[i].Size
+		wasted += fields[i].Padding
+	}
+	return wasted
+}
+
+func alignUp(n, align uintptr) uintptr {
+	if align <= 1 {
+		return n
+	}
+	return (n + align - 1) / align * align
+}
+
+// Print writes the layout as a table of the fields, followed by the suggested order when it saves
+// memory:
+//
+//	User size 32 align 8, 11 bytes wasted
+//	  Active  bool    offset 0   size 1   align 1  padding 7
+//	  Name    string  offset 8   size 16  align 8
+//	  Age     int32   offset 24  size 4   align 4  padding 4
+//	suggested order, size 24:
+//	  Name    string  offset 0   size 16  align 8
+//	  Age     int32   offset 16  size 4   align 4
+//	  Active  bool    offset 20  size 1   align 1  padding 3
+func (l *StructLayout) Print(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s size %d align %d, %d bytes wasted\n", l.Name, l.Size, l.Align, l.Wasted)
+	if err != nil {
+		return err
+	}
+	err = printLayout(w, l.Fields)
+	if err != nil {
+		return err
+	}
+	if l.SuggestedSize >= l.Size {
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "suggested order, size %d:\n", l.SuggestedSize)
+	if err != nil {
+		return err
+	}
+	return printLayout(w, l.Suggested)
+}
+
+func printLayout(w io.Writer, fields []FieldLayout) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range fields {
+		b := &strings.Builder{}
+		fmt.Fprintf(b, "  %s\t%s\toffset %d\tsize %d\talign %d", f.Name, f.Type, f.Offset, f.Size, f.Align)
+		if f.Padding > 0 {
+			fmt.Fprintf(b, "\tpadding %d", f.Padding)
+		}
+		fmt.Fprintln(tw, b.String())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,182 @@
+package reflect_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+func TestNewStructLayout(t *testing.T) {
+	type placed struct {
+		name    string
+		offset  uintptr
+		padding uintptr
+	}
+	field := func(name string, offset, size, align uintptr) refl.FieldLayout {
+		return refl.FieldLayout{Name: name, Offset: offset, Size: size, Align: align}
+	}
+
+	tests := []struct {
+		name          string
+		size, align   uintptr
+		fields        []refl.FieldLayout
+		wasted        uintptr
+		padding       []uintptr
+		suggested     []placed
+		suggestedSize uintptr
+	}{
+		{
+			name:  "reordered",
+			size:  32,
+			align: 8,
+			fields: []refl.FieldLayout{
+				field("Active", 0, 1, 1), field("Name", 8, 16, 8), field("Age", 24, 4, 4),
+			},
+			wasted:        11,
+			padding:       []uintptr{7, 0, 4},
+			suggested:     []placed{{"Name", 0, 0}, {"Age", 16, 0}, {"Active", 20, 3}},
+			suggestedSize: 24,
+		},
+		{
+			name:  "stable among equal alignments",
+			size:  24,
+			align: 8,
+			fields: []refl.FieldLayout{
+				field("A", 0, 1, 1), field("N", 8, 8, 8), field("B", 16, 1, 1),
+			},
+			wasted:        14,
+			padding:       []uintptr{7, 0, 7},
+			suggested:     []placed{{"N", 0, 0}, {"A", 8, 0}, {"B", 9, 6}},
+			suggestedSize: 16,
+		},
+		{
+			name:  "optimal",
+			size:  16,
+			align: 8,
+			fields: []refl.FieldLayout{
+				field("N", 0, 8, 8), field("A", 8, 4, 4), field("B", 12, 4, 4),
+			},
+			padding:       []uintptr{0, 0, 0},
+			suggested:     []placed{{"N", 0, 0}, {"A", 8, 0}, {"B", 12, 0}},
+			suggestedSize: 16,
+		},
+		{
+			name:  "smaller alignment first cannot be improved",
+			size:  8,
+			align: 4,
+			fields: []refl.FieldLayout{
+				field("A", 0, 2, 2), field("B", 2, 2, 2), field("N", 4, 4, 4),
+			},
+			padding:       []uintptr{0, 0, 0},
+			suggested:     []placed{{"A", 0, 0}, {"B", 2, 0}, {"N", 4, 0}},
+			suggestedSize: 8,
+		},
+		{
+			name:  "trailing zero sized field",
+			size:  16,
+			align: 8,
+			fields: []refl.FieldLayout{
+				field("N", 0, 8, 8), field("Z", 8, 0, 1),
+			},
+			wasted:        8,
+			padding:       []uintptr{0, 8},
+			suggested:     []placed{{"Z", 0, 0}, {"N", 0, 0}},
+			suggestedSize: 8,
+		},
+		{
+			name:          "empty",
+			align:         1,
+			suggestedSize: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := refl.NewStructLayout("T", tt.size, tt.align, tt.fields)
+			if l.Wasted != tt.wasted {
+				t.Errorf("Wasted %d, want %d", l.Wasted, tt.wasted)
+			}
+			for i, f := range l.Fields {
+				if f.Padding != tt.padding[i] {
+					t.Errorf("%s padding %d, want %d", f.Name, f.Padding, tt.padding[i])
+				}
+			}
+
+			if l.SuggestedSize != tt.suggestedSize {
+				t.Errorf("SuggestedSize %d, want %d", l.SuggestedSize, tt.suggestedSize)
+			}
+			var suggested []placed
+			for _, f := range l.Suggested {
+				suggested = append(suggested, placed{f.Name, f.Offset, f.Padding})
+			}
+			if !reflect.DeepEqual(suggested, tt.suggested) {
+				t.Errorf("Suggested %v, want %v", suggested, tt.suggested)
+			}
+		})
+	}
+}
+
+func TestStructLayoutPrint(t *testing.T) {
+	l := refl.NewStructLayout("User", 32, 8, []refl.FieldLayout{
+		{Name: "Active", Type: "bool", Offset: 0, Size: 1, Align: 1},
+		{Name: "Name", Type: "string", Offset: 8, Size: 16, Align: 8},
+		{Name: "Age", Type: "int32", Offset: 24, Size: 4, Align: 4},
+	})
+
+	var b bytes.Buffer
+	err := l.Print(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `User size 32 align 8, 11 bytes wasted
+  Active  bool    offset 0   size 1   align 1  padding 7
+  Name    string  offset 8   size 16  align 8
+  Age     int32   offset 24  size 4   align 4  padding 4
+suggested order, size 24:
+  Name    string  offset 0   size 16  align 8
+  Age     int32   offset 16  size 4   align 4
+  Active  bool    offset 20  size 1   align 1  padding 3
+`
+	if b.String() != want {
+		t.Errorf("Print() wrote\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	type padded struct {
+		A bool
+		N int64
+		B bool
+	}
+
+	typ := reflect.TypeOf(padded{})
+	for _, input := range []reflect.Type{typ, reflect.PtrTo(typ)} {
+		l, err := refl.Layout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Name != "padded" || l.Size != typ.Size() || l.Align != uintptr(typ.Align()) {
+			t.Errorf("layout %s size %d align %d, want padded size %d align %d",
+				l.Name, l.Size, l.Align, typ.Size(), typ.Align())
+		}
+		for i, f := range l.Fields {
+			sf := typ.Field(i)
+			if f.Name != sf.Name || f.Type != sf.Type.String() || f.Offset != sf.Offset {
+				t.Errorf("field %+v, want %s %s at %d", f, sf.Name, sf.Type, sf.Offset)
+			}
+		}
+		var used uintptr
+		for _, f := range l.Fields {
+			used += f.Size
+		}
+		if used+l.Wasted != l.Size {
+			t.Errorf("fields take %d bytes and %d are wasted, want them to add up to %d", used, l.Wasted, l.Size)
+		}
+	}
+
+	_, err := refl.Layout(reflect.TypeOf(0))
+	if err == nil {
+		t.Errorf("Layout(int) succeeded, want an error")
+	}
+}
@@ -0,0 +1,441 @@
+package reflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownPath is returned when a path refers to a field, key or element that does not exist.
+	ErrUnknownPath = errors.New("unknown path")
+	// ErrUnexported is returned when a path goes through an unexported field.
+	ErrUnexported = errors.New("unexported field")
+	// ErrNil is returned by Get when a path goes through a nil pointer, map or interface.
+	ErrNil = errors.New("nil value")
+	// ErrType is returned when a path does not apply to the kind of value, eg an index into
+	// a struct, or when the value set cannot be converted to the type of the field.
+	ErrType = errors.New("incompatible type")
+	// ErrSyntax is returned for malformed paths.
+	ErrSyntax = errors.New("invalid path syntax")
+)
+
+// PathError records the path a Get or a Set failed at.
+type PathError struct {
+	Op string // get or set
+	// Path is the part of the path that failed, eg Address.Street.
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+type (
+	// segment is a step of a path, a field name or an index or key in square brackets.
+	segment struct {
+		name  string
+		key   string
+		index bool // the segment is in square brackets.
+	}
+
+	// accessor resolves a path within a value.
+	accessor struct {
+		op     string
+		tagKey string // fields are looked up by the names in this tag, eg json.
+		set    bool
+	}
+)
+
+// Get returns the value at the path within v, a struct or a pointer to a struct. Paths are dotted
+// field names, with slice and array indices and map keys in square brackets, eg Users[3].Address.City
+// or Meta["eu"].Lat. Fields of embedded structs are promoted. A path prefixed with a tag key looks
+// fields up by their names in that tag, eg json:address.latlng.lat, untagged fields by their names.
+func Get(v interface{}, path string) (interface{}, error) {
+	a := &accessor{op: "get"}
+	segs, err := a.parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, &PathError{Op: a.op, Path: path, Err: fmt.Errorf("%w: the input is nil", ErrNil)}
+	}
+	var found reflect.Value
+	err = a.walk(rv, segs, "", func(fv reflect.Value) error {
+		found = fv
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found.CanInterface() {
+		return nil, &PathError{Op: a.op, Path: path, Err: ErrUnexported}
+	}
+	return found.Interface(), nil
+}
+
+// Set sets the value at the path within the struct v points to, see Get for the paths. Nil
+// pointers and maps on the way are allocated. The value is converted to the type of the field
+// when it is not assignable, eg an int to a float64. A nil value sets the zero value.
+func Set(v interface{}, path string, value interface{}) error {
+	a := &accessor{op: "set", set: true}
+	segs, err := a.parse(path)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &PathError{Op: a.op, Path: path, Err: fmt.Errorf("%w: %T is not a non-nil pointer", ErrType, v)}
+	}
+
+	return a.walk(rv, segs, "", func(fv reflect.Value) error {
+		if !fv.CanSet() {
+			return &PathError{Op: a.op, Path: path, Err: ErrUnexported}
+		}
+		nv, err := convert(value, fv.Type())
+		if err != nil {
+			return &PathError{Op: a.op, Path: path, Err: err}
+		}
+		fv.Set(nv)
+		return nil
+	})
+}
+
+// convert returns value as a value of type t.
+func convert(value interface{}, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(t), nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(t):
+		return v, nil
+	// converting numbers to strings yields runes, eg 65 to "A", rather than what is expected.
+	case t.Kind() == reflect.String && v.Kind() != reflect.String && !isBytes(v.Type()):
+	case v.Type().ConvertibleTo(t):
+		return v.Convert(t), nil
+	}
+	return reflect.Value{}, fmt.Errorf("%w: cannot set %s to %s", ErrType, v.Type(), t)
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && (t.Elem().Kind() == reflect.Uint8 || t.Elem().Kind() == reflect.Int32)
+}
+
+// parse splits the path into segments, setting the tag key of a tag path.
+func (a *accessor) parse(path string) ([]segment, error) {
+	syntaxErr := func(msg string) error {
+		return &PathError{Op: a.op, Path: path, Err: fmt.Errorf("%w: %s", ErrSyntax, msg)}
+	}
+
+	p := path
+	if i := strings.IndexAny(p, ":.["); i > 0 && p[i] == ':' {
+		a.tagKey, p = p[:i], p[i+1:]
+	}
+	if p == "" {
+		return nil, syntaxErr("empty path")
+	}
+
+	var segs []segment
+	for p != "" {
+		switch p[0] {
+		case '[':
+			end := closingBracket(p)
+			if end < 0 {
+				return nil, syntaxErr("unclosed [")
+			}
+			key := p[1:end]
+			if strings.HasPrefix(key, `"`) {
+				k, err := strconv.Unquote(key)
+				if err != nil {
+					return nil, syntaxErr("bad quoted key " + key)
+				}
+				key = k
+			}
+			segs = append(segs, segment{key: key, index: true})
+			p = p[end+1:]
+
+		case '.':
+			if len(segs) == 0 || len(p) == 1 {
+				return nil, syntaxErr("misplaced .")
+			}
+			p = p[1:]
+			if p[0] == '.' || p[0] == '[' {
+				return nil, syntaxErr("misplaced .")
+			}
+
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if len(segs) > 0 && !strings.HasSuffix(path[:len(path)-len(p)], ".") {
+				return nil, syntaxErr("missing . before " + p[:end])
+			}
+			segs = append(segs, segment{name: p[:end]})
+			p = p[end:]
+		}
+	}
+	return segs, nil
+}
+
+// closingBracket returns the index of the ] closing the [ p starts with, skipping quoted keys.
+func closingBracket(p string) int {
+	inQuote := false
+	for i := 1; i < len(p); i++ {
+		switch {
+		case inQuote && p[i] == '\\':
+			i++
+		case p[i] == '"':
+			inQuote = !inQuote
+		case !inQuote && p[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// walk resolves the segments from v and calls found with the value they lead to. done is the path
+// resolved so far. Map elements are not addressable: when setting, the element is copied,
+// the rest of the path resolved in the copy and the copy stored back.
+func (a *accessor) walk(v reflect.Value, segs []segment, done string, found func(reflect.Value) error) error {
+	fail := func(err error) error {
+		return &PathError{Op: a.op, Path: done, Err: err}
+	}
+	if !v.IsValid() {
+		return fail(ErrNil)
+	}
+
+	// pointers and interfaces are followed, nil pointers allocated when setting.
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if len(segs) == 0 {
+			break
+		}
+		if v.IsNil() {
+			if !a.set || v.Kind() == reflect.Interface {
+				return fail(ErrNil)
+			}
+			if !v.CanSet() {
+				return fail(ErrUnexported)
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Kind() == reflect.Interface && v.Elem().Kind() != reflect.Ptr && a.set {
+			// a value held by an interface is not addressable, it is replaced by a modified copy.
+			c := reflect.New(v.Elem().Type()).Elem()
+			c.Set(v.Elem())
+			err := a.walk(c, segs, done, found)
+			if err != nil {
+				return err
+			}
+			if !v.CanSet() {
+				return fail(ErrUnexported)
+			}
+			v.Set(c)
+			return nil
+		}
+		v = v.Elem()
+	}
+	if len(segs) == 0 {
+		return found(v)
+	}
+
+	seg := segs[0]
+	switch {
+	case !seg.index:
+		if v.Kind() != reflect.Struct {
+			return fail(fmt.Errorf("%w: %s is not a struct", ErrType, v.Type()))
+		}
+		path := fieldPath(done, seg.name)
+		fv, err := a.field(v, seg.name)
+		if err != nil {
+			return &PathError{Op: a.op, Path: path, Err: err}
+		}
+		return a.walk(fv, segs[1:], path, found)
+
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		path := done + "[" + seg.key + "]"
+		i, err := strconv.Atoi(seg.key)
+		if err != nil {
+			return &PathError{Op: a.op, Path: path, Err: fmt.Errorf("%w: index %q is not a number", ErrSyntax, seg.key)}
+		}
+		if i < 0 || i >= v.Len() {
+			return &PathError{Op: a.op, Path: path, Err: fmt.Errorf("%w: index %d out of range", ErrUnknownPath, i)}
+		}
+		return a.walk(v.Index(i), segs[1:], path, found)
+
+	case v.Kind() == reflect.Map:
+		if v.IsNil() && !a.set {
+			return fail(ErrNil)
+		}
+		path := done + keyPath(seg.key, v.Type().Key())
+		k, err := mapKey(seg.key, v.Type().Key())
+		if err != nil {
+			return &PathError{Op: a.op, Path: path, Err: err}
+		}
+		return a.mapElem(v, k, segs[1:], path, found)
+
+	default:
+		return fail(fmt.Errorf("%w: %s cannot be indexed", ErrType, v.Type()))
+	}
+}
+
+// mapElem resolves the rest of the path from the element of the map m at key k.
+func (a *accessor) mapElem(m, k reflect.Value, segs []segment, path string, found func(reflect.Value) error) error {
+	e := m.MapIndex(k)
+	if !a.set {
+		if !e.IsValid() {
+			return &PathError{Op: a.op, Path: path, Err: ErrUnknownPath}
+		}
+		return a.walk(e, segs, path, found)
+	}
+
+	if m.IsNil() {
+		if !m.CanSet() {
+			return &PathError{Op: a.op, Path: path, Err: ErrUnexported}
+		}
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	c := reflect.New(m.Type().Elem()).Elem()
+	if e.IsValid() {
+		c.Set(e)
+	}
+	err := a.walk(c, segs, path, found)
+	if err != nil {
+		return err
+	}
+	if !m.CanInterface() {
+		return &PathError{Op: a.op, Path: path, Err: ErrUnexported}
+	}
+	m.SetMapIndex(k, c)
+	return nil
+}
+
+// field returns the field of the struct v named name, looking fields of embedded structs up too.
+// Nil embedded pointers are allocated when setting.
+func (a *accessor) field(v reflect.Value, name string) (reflect.Value, error) {
+	index, ok := a.fieldIndex(v.Type(), name)
+	if !ok {
+		return reflect.Value{}, ErrUnknownPath
+	}
+
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !a.set {
+					return reflect.Value{}, ErrNil
+				}
+				if !v.CanSet() {
+					return reflect.Value{}, ErrUnexported
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Type().Field(x).PkgPath != "" && !v.Type().Field(x).Anonymous {
+			return reflect.Value{}, ErrUnexported
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// fieldIndex returns the index sequence of the field of t named name, the fields of t first and
+// then, level by level, the fields promoted from embedded structs.
+func (a *accessor) fieldIndex(t reflect.Type, name string) ([]int, bool) {
+	type candidate struct {
+		t     reflect.Type
+		index []int
+	}
+	level := []candidate{{t: t}}
+	seen := map[reflect.Type]bool{}
+
+	for len(level) > 0 {
+		var next []candidate
+		for _, c := range level {
+			if seen[c.t] {
+				continue
+			}
+			seen[c.t] = true
+
+			for i := 0; i < c.t.NumField(); i++ {
+				sf := c.t.Field(i)
+				index := append(append([]int(nil), c.index...), i)
+				if a.matches(sf, name) {
+					return index, true
+				}
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if ft.Kind() == reflect.Struct {
+						next = append(next, candidate{t: ft, index: index})
+					}
+				}
+			}
+		}
+		level = next
+	}
+	return nil, false
+}
+
+// matches tells whether the field is named name, in the tag of the accessor when it has one.
+func (a *accessor) matches(sf reflect.StructField, name string) bool {
+	if a.tagKey == "" {
+		return sf.Name == name
+	}
+
+	tag, ok := sf.Tag.Lookup(a.tagKey)
+	if !ok || tag == "" || strings.HasPrefix(tag, ",") {
+		// untagged fields are known by their names, as encoding/json does.
+		return !sf.Anonymous && strings.EqualFold(sf.Name, name)
+	}
+	n := strings.Split(tag, ",")[0]
+	return n != "-" && n == name
+}
+
+// mapKey converts the key of a path segment to the key type of a map.
+func mapKey(key string, t reflect.Type) (reflect.Value, error) {
+	var (
+		v   interface{}
+		err error
+	)
+	switch t.Kind() {
+	case reflect.String:
+		v = key
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err = strconv.ParseInt(key, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v, err = strconv.ParseUint(key, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(key, t.Bits())
+	case reflect.Bool:
+		v, err = strconv.ParseBool(key)
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: map keys of type %s are not supported", ErrType, t)
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: key %q is not a %s", ErrSyntax, key, t)
+	}
+	return reflect.ValueOf(v).Convert(t), nil
+}
+
+// keyPath returns the path segment of a map key, quoted for string keys as in FieldInfo paths.
+func keyPath(key string, t reflect.Type) string {
+	if t.Kind() == reflect.String {
+		return "[" + strconv.Quote(key) + "]"
+	}
+	return "[" + key + "]"
+}
@@ -0,0 +1,58 @@
+package reflect_test
+
+import (
+	"errors"
+	"testing"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+func TestGetSetNil(t *testing.T) {
+	type inner struct {
+		X int
+	}
+	type outer struct {
+		I interface{}
+		P *inner
+		M map[string]inner
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		call func() error
+	}{
+		{name: "get nil", err: refl.ErrNil, call: func() error {
+			_, err := refl.Get(nil, "X")
+			return err
+		}},
+		{name: "get through nil interface", err: refl.ErrNil, call: func() error {
+			_, err := refl.Get(outer{}, "I.X")
+			return err
+		}},
+		{name: "get through nil pointer", err: refl.ErrNil, call: func() error {
+			_, err := refl.Get(outer{}, "P.X")
+			return err
+		}},
+		{name: "get through nil map", err: refl.ErrNil, call: func() error {
+			_, err := refl.Get(outer{}, `M["eu"].X`)
+			return err
+		}},
+		{name: "get missing key", err: refl.ErrUnknownPath, call: func() error {
+			_, err := refl.Get(outer{M: map[string]inner{}}, `M["eu"].X`)
+			return err
+		}},
+		{name: "set nil", err: refl.ErrType, call: func() error {
+			return refl.Set(nil, "X", 1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			var pe *refl.PathError
+			if !errors.As(err, &pe) || !errors.Is(err, tt.err) {
+				t.Errorf("error %v, want a *PathError wrapping %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package reflect
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Print writes the schema as an indented tree, one field or element per line with its type, kind,
+// offset, tags and, when it can be read, its value. Empty collections show their element type:
+//
+//	User
+//	  Name string (string) offset 0 env:"USER_NAME" envDefault:"Lucien" = "Rebecca"
+//	  Address main.Address (struct) offset 24
+//	    Street string (string) offset 0 env:"USER_ADDRESS_STREET,required" = "16 St Mary's Close"
+func (s *Schema) Print(w io.Writer) error {
+	_, err := fmt.Fprintln(w, s.Type.Name())
+	if err != nil {
+		return err
+	}
+	return printFields(w, s.Fields, 1)
+}
+
+func printFields(w io.Writer, ff []*FieldInfo, level int) error {
+	for _, f := range ff {
+		b := &strings.Builder{}
+		fmt.Fprintf(b, "%s%s %s (%s)", strings.Repeat("  ", level), f.Name, f.Type, f.Kind)
+		if !strings.HasPrefix(f.Name, "[") { // elements of collections have no offset.
+			fmt.Fprintf(b, " offset %d", f.Offset)
+		}
+		for _, t := range f.Tags {
+			fmt.Fprintf(b, " %s", t)
+		}
+
+		var flags []string
+		if !f.Exported {
+			flags = append(flags, "unexported")
+		}
+		if f.Embedded {
+			flags = append(flags, "embedded")
+		}
+		if len(flags) > 0 {
+			fmt.Fprintf(b, " [%s]", strings.Join(flags, ", "))
+		}
+
+		switch {
+		case f.Cycle != nil:
+			fmt.Fprintf(b, " -> %s", f.CycleString())
+		case f.Pointer && f.Value.IsValid() && f.Value.IsNil():
+			b.WriteString(" = nil")
+		case len(f.Children) == 0 && f.Elem == nil && f.Value.IsValid() && f.Value.CanInterface():
+			fmt.Fprintf(b, " = %s", formatValue(f.Value))
+		}
+
+		_, err := fmt.Fprintln(w, b.String())
+		if err != nil {
+			return err
+		}
+		err = printFields(w, f.Children, level+1)
+		if err != nil {
+			return err
+		}
+		// the element type of an empty collection still describes what it would hold.
+		if len(f.Children) == 0 && f.Elem != nil {
+			err = printFields(w, []*FieldInfo{f.Elem}, level+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return strconv.Quote(v.String())
+	}
+	return fmt.Sprintf("%+v", v.Interface())
+}
+
+// CycleString describes the back-reference of a cycle, eg <cycle: Node> or <cycle: Node at Children[0]>
+// when the value referred to is not the described struct.
+func (fi *FieldInfo) CycleString() string {
+	if fi.Cycle == nil {
+		return ""
+	}
+	name := fi.Cycle.Name()
+	if name == "" {
+		name = fi.Cycle.String()
+	}
+	if fi.CyclePath == "" {
+		return "<cycle: " + name + ">"
+	}
+	return "<cycle: " + name + " at " + fi.CyclePath + ">"
+}
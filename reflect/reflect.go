@@ -2,124 +2,226 @@ package reflect
 
 import (
 	"fmt"
-	"log"
 	"reflect"
-	"strings"
+	"strconv"
 )
 
 type (
-	tagInfo struct {
-		envName    string
-		required   bool
-		envDefault string
+	// Schema describes a data structure and the values it holds.
+	Schema struct {
+		// Type is the type of the struct described, eg User.
+		Type reflect.Type
+		// Fields are the fields of the struct.
+		Fields []*FieldInfo
 	}
-)
 
-// Parse expects the provided data structure and reports on its content. The input must be
-// a pointer to a struct.
-func Parse(c interface{}) error {
-	log.Println("-----------------------------------------------------")
-	defer func() {
-		log.Println("-----------------------------------------------------")
-	}()
+	// FieldInfo describes a struct field, or an element of a slice, an array or a map, and what
+	// it holds: the fields of a struct, the elements of a collection, the value an interface holds.
+	FieldInfo struct {
+		// Name is the name of the field, eg Street, or the index or key of an element, eg [3] or ["eu"].
+		Name string
+		// Path is the path of the field from the described struct, eg Users[3].Address.Street or
+		// Meta["eu"].Lat. The path of the element type of a collection ends with [], eg Users[].
+		Path string
+		// Index is the index of the field in its struct, as used by reflect.Value.Field, or the index
+		// of a slice or array element. It is -1 for map elements.
+		Index int
+		// Key is the key of a map element.
+		Key reflect.Value
+		// Offset is the offset of the field in its struct, in bytes.
+		Offset uintptr
+		Kind   reflect.Kind
+		Type   reflect.Type
+		// StructTag is the struct tag of the field as written in the source, Tags its parsed form.
+		StructTag reflect.StructTag
+		// Tags are the parsed struct tags of the field, in the order they appear.
+		Tags     []Tag
+		Exported bool
+		// Embedded is true for anonymous fields.
+		Embedded bool
+		// Pointer is true when the field is a pointer, Children then describe what it points to.
+		Pointer bool
+		// Value is the value of the field. It is settable when the described struct was provided
+		// by pointer and the field is exported, map elements never are. It is invalid in a schema
+		// described from a type.
+		Value reflect.Value
+		// Children are the fields of a nested struct or the elements of a collection, map elements
+		// sorted by key. They are empty for nil pointers and interfaces.
+		Children []*FieldInfo
+		// Elem describes the element type of a slice, an array or a map, even an empty one.
+		Elem *FieldInfo
+
+		// Cycle is set when what the field holds is already being described by an enclosing node,
+		// eg the Next field of the last node of a circular list, or a Parent field. It is the type
+		// of the value the back-reference points to, described at CyclePath ("" for the described
+		// struct itself). Children are then empty.
+		Cycle     reflect.Type
+		CyclePath string
+	}
+)
 
+// Parse returns the *Schema of the provided data structure, its fields described with the values
+// they hold. The input must be a pointer to a struct.
+func Parse(c interface{}) (*Schema, error) {
 	// creates a new initialised concrete type stored in the provided interface c.
 	v := reflect.ValueOf(c)
 
 	// the provided concrete type must be a pointer.
 	if v.Kind() != reflect.Ptr {
-		return fmt.Errorf("input %+v must be a pointer", c)
+		return nil, fmt.Errorf("input %+v must be a pointer", c)
 	}
 
 	// now we need the value that the interface v contains.
 	e := v.Elem()
 	if e.Kind() != reflect.Struct {
-		return fmt.Errorf("the dynamic type of the input %+v must be a struct", e)
+		return nil, fmt.Errorf("the dynamic type of the input %+v must be a struct", e)
 	}
 
-	err := parse(e)
+	w := newWalker(nil)
+	w.visiting[visit{ptr: v.Pointer(), typ: v.Type()}] = ""
+	ff, err := w.fields(e, "")
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return &Schema{
+		Type:   e.Type(),
+		Fields: ff,
+	}, nil
+}
+
+// Describe returns the schema of the struct type t, or of the struct t points to, without values.
+// Collections are only described by their element types.
+func Describe(t reflect.Type) (*Schema, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %s must be a struct", t)
 	}
 
-	return nil
+	return &Schema{
+		Type:   t,
+		Fields: describe(t, "", map[reflect.Type]string{t: ""}),
+	}, nil
 }
 
-// parse accepts a struct value.
-func parse(v reflect.Value) error {
-	log.Printf("PARSE INPUT: v [%+v]\n", v.Type().Name())
-	t := v.Type() // type of the struct, eg Address (=> t.Name() = Address)
+// keyName returns the path segment of a map key, eg ["eu"] or [3].
+func keyName(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return "[" + strconv.Quote(k.String()) + "]"
+	}
+	if !k.CanInterface() {
+		return "[" + k.Type().String() + "]"
+	}
+	return fmt.Sprintf("[%v]", k.Interface())
+}
 
-	fmt.Println("++++++++++++++++++++++++++++++++++++++++++++")
-	log.Printf("\t*** t.Name(): [%+v]\n", t.Name())
-	log.Printf("\t*** v: [%+v] v.Kind() [%+v]\n", v, v.Kind())
+func newField(tf reflect.StructField, path string) *FieldInfo {
+	return &FieldInfo{
+		Name:      tf.Name,
+		Path:      fieldPath(path, tf.Name),
+		Index:     tf.Index[len(tf.Index)-1],
+		Offset:    tf.Offset,
+		Kind:      tf.Type.Kind(),
+		Type:      tf.Type,
+		StructTag: tf.Tag,
+		Tags:      ParseTags(tf.Tag),
+		Exported:  tf.PkgPath == "", // PkgPath is empty for exported fields.
+		Embedded:  tf.Anonymous,
+		Pointer:   tf.Type.Kind() == reflect.Ptr,
+	}
+}
 
+// describe returns the nodes of the fields of the struct type t. stack holds the struct types
+// being described with their paths, a field referring to one of them is a cycle.
+func describe(t reflect.Type, path string, stack map[reflect.Type]string) []*FieldInfo {
+	ff := make([]*FieldInfo, 0, t.NumField())
 	for i := 0; i < t.NumField(); i++ {
-		f := v.Field(i)
-		tf := t.Field(i) // eg tf.Type.Name() == LatLng
-
-		fmt.Println("--------------------------------------------")
-		log.Printf("\t\t*** Field ... f.Type() == f.Type().Name(): [%+v], tf.Type == tf.Type.Name(): [%+v]\n",
-			f.Type(), tf.Type)
-
-		log.Printf("\t\t\t<<< Struct Type Field tf: [%+v] (tf.Type [%+v]) - tf.Type.Kind() [%+v]\n",
-			tf, tf.Type, tf.Type.Kind())
-		log.Printf("\t\t\t>>> Struct Field f: [%+v] (f.Type().Kind() [%+v]) - f.Kind() [%+v]\n",
-			f, f.Type().Kind(), f.Kind())
-
-		// struct field is a non-nil pointer.
-		if f.Kind() == reflect.Ptr && !f.IsNil() {
-			log.Println("\t\t== pointer and not nil ...")
-
-			log.Printf("\t\t== f type name: [%+v]\n", f.Type().Name())
-			err := Parse(f.Interface()) // Parse accepts an interface type, so we get the f value as an interface
-			if err != nil {
-				return err
-			}
-			continue
-		}
+		fi := newField(t.Field(i), path)
+		fi.describe(stack)
+		ff = append(ff, fi)
+	}
+	return ff
+}
 
-		// struct field itself is a struct.
-		// Addr refers to memory address
-		//if f.Kind() == reflect.Struct && f.CanAddr() && f.Type().Name() == "" {
-		if f.Kind() == reflect.Struct && f.CanAddr() {
-			log.Println("\t\t-- struct and addressable ...")
-
-			log.Printf("\t\t-- f.Addr(): %+v ... f.Type().Name(): [%+v] ... t.Name(): [%+v]\n",
-				f.Addr(), f.Type().Name(), t.Name())
-			// f.Addr() returns a pointer to the struct f and Interface() returns the value of f as an interface.
-			err := Parse(f.Addr().Interface())
-			if err != nil {
-				return err
-			}
-			continue
-		}
+// describe sets the children of the node from its type, the fields of a struct or of the struct
+// it points to, and the element type of a collection.
+func (fi *FieldInfo) describe(stack map[reflect.Type]string) {
+	fi.Elem = elem(fi.Type, fi.Path, stack)
+
+	t := fi.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	if p, ok := stack[t]; ok {
+		fi.Cycle, fi.CyclePath = t, p
+		return
+	}
+
+	stack[t] = fi.Path
+	defer delete(stack, t)
+	fi.Children = describe(t, fi.Path, stack)
+}
 
-		ti := processTag(tf)
-		log.Printf("\t\t\t--> Tag Info: [%+v]\n", ti)
-		fmt.Println("--------------------------------------------")
+// elem returns the node describing the element type of a collection, pointers followed. It is nil
+// for other types.
+func elem(t reflect.Type, path string, stack map[reflect.Type]string) *FieldInfo {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return nil
 	}
-	fmt.Println("++++++++++++++++++++++++++++++++++++++++++++")
 
-	return nil
+	et := t.Elem()
+	e := &FieldInfo{
+		Name:     "[]",
+		Path:     path + "[]",
+		Index:    -1,
+		Kind:     et.Kind(),
+		Type:     et,
+		Exported: true,
+		Pointer:  et.Kind() == reflect.Ptr,
+	}
+	e.describe(stack)
+	return e
 }
 
-func processTag(sf reflect.StructField) tagInfo {
-	ti := tagInfo{}
+// Field returns the field at the dotted path, eg Address.LatLng, or nil when there is none.
+func (s *Schema) Field(path string) *FieldInfo {
+	var find func(ff []*FieldInfo) *FieldInfo
+	find = func(ff []*FieldInfo) *FieldInfo {
+		for _, f := range ff {
+			if f.Path == path {
+				return f
+			}
+			if fc := find(f.Children); fc != nil {
+				return fc
+			}
+		}
+		return nil
+	}
+	return find(s.Fields)
+}
 
-	t, okE := sf.Tag.Lookup("env")
-	if okE {
-		p := strings.Split(t, ",")
-		ti.envName = p[0]
-		if len(p) == 2 && p[1] == "required" {
-			ti.required = true
-			return ti
+// Tag returns the parsed tag with the key, eg env.
+func (fi *FieldInfo) Tag(key string) (Tag, bool) {
+	for _, t := range fi.Tags {
+		if t.Key == key {
+			return t, true
 		}
 	}
+	return Tag{}, false
+}
 
-	d, okD := sf.Tag.Lookup("envDefault")
-	if okD {
-		ti.envDefault = d
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
 	}
-	return ti
+	return path + "." + name
 }
@@ -0,0 +1,469 @@
+package reflect_test
+
+import (
+	"reflect"
+	"testing"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+type (
+	latLng struct {
+		Lat, Lng float64
+	}
+
+	address struct {
+		Street string `json:"street" env:"STREET,required"`
+		LatLng *latLng
+	}
+
+	Base struct {
+		ID int
+	}
+
+	user struct {
+		Base
+		Name    string `json:"name,omitempty"`
+		age     int
+		Address address
+		Home    *address
+	}
+)
+
+func TestParseSchema(t *testing.T) {
+	u := &user{Name: "ann", age: 30, Address: address{Street: "Main", LatLng: &latLng{Lat: 1}}}
+	s, err := refl.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Type != reflect.TypeOf(user{}) {
+		t.Errorf("Type %s, want user", s.Type)
+	}
+
+	tests := []struct {
+		path     string
+		name     string
+		index    int
+		kind     reflect.Kind
+		exported bool
+		embedded bool
+		pointer  bool
+		children []string
+		value    interface{}
+		settable bool
+	}{
+		{path: "Base", name: "Base", kind: reflect.Struct, exported: true, embedded: true,
+			children: []string{"Base.ID"}, value: Base{}, settable: true},
+		{path: "Base.ID", name: "ID", kind: reflect.Int, exported: true, value: 0, settable: true},
+		{path: "Name", name: "Name", index: 1, kind: reflect.String, exported: true, value: "ann", settable: true},
+		{path: "age", name: "age", index: 2, kind: reflect.Int},
+		{path: "Address", name: "Address", index: 3, kind: reflect.Struct, exported: true,
+			children: []string{"Address.Street", "Address.LatLng"}, value: u.Address, settable: true},
+		{path: "Address.Street", name: "Street", kind: reflect.String, exported: true, value: "Main", settable: true},
+		{path: "Address.LatLng", name: "LatLng", index: 1, kind: reflect.Ptr, exported: true, pointer: true,
+			children: []string{"Address.LatLng.Lat", "Address.LatLng.Lng"}, value: u.Address.LatLng, settable: true},
+		{path: "Address.LatLng.Lat", name: "Lat", kind: reflect.Float64, exported: true, value: 1.0, settable: true},
+		{path: "Home", name: "Home", index: 4, kind: reflect.Ptr, exported: true, pointer: true,
+			value: (*address)(nil), settable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			f := s.Field(tt.path)
+			if f == nil {
+				t.Fatalf("no field %s", tt.path)
+			}
+			if f.Name != tt.name || f.Index != tt.index || f.Kind != tt.kind {
+				t.Errorf("name %s, index %d, kind %s, want %s, %d, %s", f.Name, f.Index, f.Kind, tt.name, tt.index, tt.kind)
+			}
+			if f.Exported != tt.exported || f.Embedded != tt.embedded || f.Pointer != tt.pointer {
+				t.Errorf("exported %t, embedded %t, pointer %t, want %t, %t, %t",
+					f.Exported, f.Embedded, f.Pointer, tt.exported, tt.embedded, tt.pointer)
+			}
+
+			var children []string
+			for _, c := range f.Children {
+				children = append(children, c.Path)
+			}
+			if !reflect.DeepEqual(children, tt.children) {
+				t.Errorf("children %v, want %v", children, tt.children)
+			}
+
+			if f.Value.CanSet() != tt.settable {
+				t.Errorf("settable %t, want %t", f.Value.CanSet(), tt.settable)
+			}
+			if tt.exported && !reflect.DeepEqual(f.Value.Interface(), tt.value) {
+				t.Errorf("value %v, want %v", f.Value.Interface(), tt.value)
+			}
+		})
+	}
+
+	if len(s.Fields) != 5 {
+		t.Errorf("%d top level fields, want 5", len(s.Fields))
+	}
+	if f := s.Field("Missing"); f != nil {
+		t.Errorf("Field(Missing) = %+v, want nil", f)
+	}
+
+	s.Field("Address.Street").Value.SetString("High")
+	if u.Address.Street != "High" {
+		t.Errorf("setting the field value left the struct with %q", u.Address.Street)
+	}
+}
+
+func TestParseSchemaTags(t *testing.T) {
+	s, err := refl.Parse(&user{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := s.Field("Address.Street")
+	if f.StructTag != `json:"street" env:"STREET,required"` {
+		t.Errorf("StructTag %s", f.StructTag)
+	}
+	want := []refl.Tag{
+		{Key: "json", Value: "street", Name: "street", Options: []string{}},
+		{Key: "env", Value: "STREET,required", Name: "STREET", Options: []string{"required"}},
+	}
+	if !reflect.DeepEqual(f.Tags, want) {
+		t.Errorf("Tags %+v, want %+v", f.Tags, want)
+	}
+	if tag, ok := f.Tag("env"); !ok || !tag.HasOption("required") {
+		t.Errorf("Tag(env) = %+v, %t, want the required env tag", tag, ok)
+	}
+	if _, ok := f.Tag("yaml"); ok {
+		t.Errorf("Tag(yaml) found")
+	}
+
+	off := reflect.TypeOf(address{}).Field(1).Offset
+	if f := s.Field("Address.LatLng"); f.Offset != off {
+		t.Errorf("Address.LatLng offset %d, want %d", f.Offset, off)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{name: "struct", input: user{}},
+		{name: "nil", input: nil},
+		{name: "pointer to int", input: new(int)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := refl.Parse(tt.input)
+			if err == nil {
+				t.Errorf("Parse() = %+v, want an error", s)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for _, typ := range []reflect.Type{reflect.TypeOf(user{}), reflect.TypeOf(&user{})} {
+		s, err := refl.Describe(typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// nil pointers are described from their types.
+		f := s.Field("Home.LatLng.Lng")
+		if f == nil {
+			t.Fatalf("Describe(%s) has no Home.LatLng.Lng", typ)
+		}
+		if f.Kind != reflect.Float64 || f.Value.IsValid() {
+			t.Errorf("Home.LatLng.Lng kind %s, valid value %t, want float64 without a value", f.Kind, f.Value.IsValid())
+		}
+	}
+
+	_, err := refl.Describe(reflect.TypeOf(0))
+	if err == nil {
+		t.Errorf("Describe(int) succeeded, want an error")
+	}
+}
+
+func TestParseCollectionPaths(t *testing.T) {
+	type config struct {
+		Users  []user
+		Ptrs   []*latLng
+		Pair   [2]string
+		Meta   map[string]latLng
+		Codes  map[int]string
+		Any    interface{}
+		Groups map[string][]string
+		Empty  []address
+	}
+
+	c := &config{
+		Users:  []user{{Name: "ann"}, {Name: "bob", Address: address{Street: "Main"}}},
+		Ptrs:   []*latLng{nil, {Lat: 1}},
+		Pair:   [2]string{"a", "b"},
+		Meta:   map[string]latLng{"us": {}, "eu": {Lat: 2}},
+		Codes:  map[int]string{10: "x", 2: "y"},
+		Any:    &latLng{Lng: 3},
+		Groups: map[string][]string{"admin": {"ann"}},
+	}
+	s, err := refl.Parse(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		name     string
+		index    int
+		value    interface{}
+		children []string
+	}{
+		{path: "Users", children: []string{"Users[0]", "Users[1]"}},
+		{path: "Users[1]", name: "[1]", index: 1, children: []string{"Users[1].Base", "Users[1].Name", "Users[1].age",
+			"Users[1].Address", "Users[1].Home"}},
+		{path: "Users[1].Address.Street", name: "Street", value: "Main"},
+		{path: "Ptrs[0]", name: "[0]", value: (*latLng)(nil)},
+		{path: "Ptrs[1].Lat", name: "Lat", value: 1.0},
+		{path: "Pair", children: []string{"Pair[0]", "Pair[1]"}},
+		{path: "Pair[1]", name: "[1]", index: 1, value: "b"},
+		{path: "Meta", children: []string{`Meta["eu"]`, `Meta["us"]`}},
+		{path: `Meta["eu"].Lat`, name: "Lat", value: 2.0},
+		{path: `Meta["eu"]`, name: `["eu"]`, index: -1, value: latLng{Lat: 2},
+			children: []string{`Meta["eu"].Lat`, `Meta["eu"].Lng`}},
+		{path: "Codes", children: []string{"Codes[10]", "Codes[2]"}},
+		{path: "Codes[2]", name: "[2]", index: -1, value: "y"},
+		{path: "Any", children: []string{"Any.Lat", "Any.Lng"}},
+		{path: "Any.Lng", name: "Lng", index: 1, value: 3.0},
+		{path: `Groups["admin"][0]`, name: "[0]", value: "ann"},
+		{path: "Empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			f := s.Field(tt.path)
+			if f == nil {
+				t.Fatalf("no field %s", tt.path)
+			}
+			if tt.name != "" && (f.Name != tt.name || f.Index != tt.index) {
+				t.Errorf("name %s, index %d, want %s, %d", f.Name, f.Index, tt.name, tt.index)
+			}
+			if tt.value != nil && !reflect.DeepEqual(f.Value.Interface(), tt.value) {
+				t.Errorf("value %v, want %v", f.Value.Interface(), tt.value)
+			}
+
+			var children []string
+			for _, c := range f.Children {
+				children = append(children, c.Path)
+			}
+			if !reflect.DeepEqual(children, tt.children) {
+				t.Errorf("children %v, want %v", children, tt.children)
+			}
+		})
+	}
+
+	if k := s.Field(`Meta["eu"]`).Key; k.String() != "eu" {
+		t.Errorf(`Meta["eu"] key %v, want eu`, k)
+	}
+	if s.Field(`Meta["eu"]`).Value.CanSet() {
+		t.Errorf(`Meta["eu"] is settable, map elements never are`)
+	}
+	if !s.Field("Users[0].Name").Value.CanSet() {
+		t.Errorf("Users[0].Name is not settable")
+	}
+}
+
+func TestDescribeCollectionElems(t *testing.T) {
+	type config struct {
+		Users []user
+		Meta  map[string]*latLng
+		Tags  [3]string
+		Name  string
+	}
+
+	s, err := refl.Describe(reflect.TypeOf(config{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		field    string
+		path     string
+		typ      reflect.Type
+		children []string
+	}{
+		{field: "Users", path: "Users[]", typ: reflect.TypeOf(user{}), children: []string{
+			"Users[].Base", "Users[].Name", "Users[].age", "Users[].Address", "Users[].Home"}},
+		{field: "Meta", path: "Meta[]", typ: reflect.TypeOf(&latLng{}), children: []string{"Meta[].Lat", "Meta[].Lng"}},
+		{field: "Tags", path: "Tags[]", typ: reflect.TypeOf("")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			e := s.Field(tt.field).Elem
+			if e == nil {
+				t.Fatalf("%s has no Elem", tt.field)
+			}
+			if e.Path != tt.path || e.Type != tt.typ || e.Index != -1 {
+				t.Errorf("Elem path %s, type %s, index %d, want %s, %s, -1", e.Path, e.Type, e.Index, tt.path, tt.typ)
+			}
+			var children []string
+			for _, c := range e.Children {
+				children = append(children, c.Path)
+			}
+			if !reflect.DeepEqual(children, tt.children) {
+				t.Errorf("Elem children %v, want %v", children, tt.children)
+			}
+		})
+	}
+
+	if e := s.Field("Name").Elem; e != nil {
+		t.Errorf("Name has Elem %+v, want nil", e)
+	}
+
+	// empty collections are described by their element types too.
+	p, err := refl.Parse(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := p.Field("Users").Elem; e == nil || e.Path != "Users[]" {
+		t.Errorf("empty Users Elem %+v, want Users[]", e)
+	}
+}
+
+type (
+	listNode struct {
+		Name string
+		Next *listNode
+	}
+
+	treeNode struct {
+		Name     string
+		Parent   *treeNode
+		Children []*treeNode
+	}
+)
+
+func TestParseCycles(t *testing.T) {
+	a, b, c := &listNode{Name: "a"}, &listNode{Name: "b"}, &listNode{Name: "c"}
+
+	root := &treeNode{Name: "root"}
+	root.Children = []*treeNode{{Name: "leaf", Parent: root}}
+
+	shared := &latLng{Lat: 1}
+	data := map[string]interface{}{}
+	data["self"] = data
+
+	tests := []struct {
+		name   string
+		input  interface{}
+		setup  func()
+		path   string
+		cycle  reflect.Type
+		at     string
+		noloop []string
+	}{
+		{
+			name:  "circular list",
+			input: a,
+			setup: func() { a.Next, b.Next = b, a },
+			path:  "Next.Next",
+			cycle: reflect.TypeOf(listNode{}),
+			at:    "",
+		},
+		{
+			name:  "loop below the top",
+			input: a,
+			setup: func() { a.Next, b.Next, c.Next = b, c, b },
+			path:  "Next.Next.Next",
+			cycle: reflect.TypeOf(listNode{}),
+			at:    "Next",
+		},
+		{
+			name:  "parent",
+			input: root,
+			path:  "Children[0].Parent",
+			cycle: reflect.TypeOf(treeNode{}),
+			at:    "",
+		},
+		{
+			name:  "map holding itself",
+			input: &struct{ Data map[string]interface{} }{Data: data},
+			path:  `Data["self"]`,
+			cycle: reflect.TypeOf(data),
+			at:    "Data",
+		},
+		{
+			name:   "shared pointers",
+			input:  &struct{ A, B *latLng }{A: shared, B: shared},
+			noloop: []string{"A.Lat", "B.Lat"},
+		},
+		{
+			name:   "struct and first field",
+			input:  &struct{ L listNode }{L: listNode{Name: "x"}},
+			noloop: []string{"L.Name", "L.Next"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			s, err := refl.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, p := range tt.noloop {
+				f := s.Field(p)
+				if f == nil || f.Cycle != nil {
+					t.Errorf("field %s = %+v, want it without a cycle", p, f)
+				}
+			}
+			if tt.path == "" {
+				return
+			}
+
+			f := s.Field(tt.path)
+			if f == nil {
+				t.Fatalf("no field %s", tt.path)
+			}
+			if f.Cycle != tt.cycle || f.CyclePath != tt.at {
+				t.Errorf("cycle %v at %q, want %v at %q", f.Cycle, f.CyclePath, tt.cycle, tt.at)
+			}
+			if len(f.Children) != 0 {
+				t.Errorf("back-reference has children %d, want none", len(f.Children))
+			}
+		})
+	}
+}
+
+func TestDescribeCycles(t *testing.T) {
+	s, err := refl.Describe(reflect.TypeOf(treeNode{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		node *refl.FieldInfo
+		path string
+	}{
+		{node: s.Field("Parent"), path: ""},
+		{node: s.Field("Children").Elem, path: ""},
+	}
+	for _, tt := range tests {
+		f := tt.node
+		if f.Cycle != reflect.TypeOf(treeNode{}) || f.CyclePath != tt.path || len(f.Children) != 0 {
+			t.Errorf("%s: cycle %v at %q with %d children, want treeNode at %q without children",
+				f.Path, f.Cycle, f.CyclePath, len(f.Children), tt.path)
+		}
+	}
+
+	type wrapper struct {
+		A treeNode
+		B *listNode
+	}
+	s, err = refl.Describe(reflect.TypeOf(wrapper{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := s.Field("A.Parent"); f.Cycle == nil || f.CyclePath != "A" {
+		t.Errorf("A.Parent cycle %v at %q, want treeNode at A", f.Cycle, f.CyclePath)
+	}
+	if f := s.Field("B.Next"); f.Cycle == nil || f.CyclePath != "B" {
+		t.Errorf("B.Next cycle %v at %q, want listNode at B", f.Cycle, f.CyclePath)
+	}
+}
@@ -0,0 +1,109 @@
+package reflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Tag is a parsed struct tag key, eg env:"USER_NAME,required".
+type Tag struct {
+	// Key is the tag key, eg env.
+	Key string
+	// Value is the raw, unquoted value, eg USER_NAME,required.
+	Value string
+	// Name is the value up to the first comma, eg USER_NAME.
+	Name string
+	// Options are the comma separated values following the name, eg required.
+	Options []string
+}
+
+// HasOption tells whether the tag has the option, eg omitempty.
+func (t Tag) HasOption(opt string) bool {
+	for _, o := range t.Options {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the tag as written in the source, eg env:"USER_NAME,required".
+func (t Tag) String() string {
+	return t.Key + ":" + strconv.Quote(t.Value)
+}
+
+// ParseTags parses the conventional `key:"value" key:"value"` format of struct tags. The part of
+// a malformed tag following the error is left out, see ValidateTag.
+func ParseTags(tag reflect.StructTag) []Tag {
+	tt, _ := parseTags(string(tag))
+	return tt
+}
+
+// ValidateTag returns an error when the tag does not follow the `key:"value"` convention that
+// reflect.StructTag.Get relies on.
+func ValidateTag(tag reflect.StructTag) error {
+	_, err := parseTags(string(tag))
+	return err
+}
+
+// parseTags follows the parsing of reflect.StructTag.Lookup, reporting what it silently skips.
+func parseTags(tag string) ([]Tag, error) {
+	var tt []Tag
+	for tag != "" {
+		// skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// the key is a non-empty string of non-control characters other than space, quote and colon.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 {
+			return tt, fmt.Errorf("bad syntax for struct tag key at %q", tag)
+		}
+		if i+1 >= len(tag) || tag[i] != ':' {
+			return tt, fmt.Errorf("bad syntax for struct tag pair %q, expected key:\"value\"", tag)
+		}
+		if tag[i+1] != '"' {
+			return tt, fmt.Errorf("bad syntax for struct tag value of %s, it must be double quoted", tag[:i])
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// scan the quoted value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return tt, fmt.Errorf("bad syntax for struct tag value of %s, the quote is not closed", key)
+		}
+		qvalue := tag[:i+1]
+		tag = tag[i+1:]
+
+		value, err := strconv.Unquote(qvalue)
+		if err != nil {
+			return tt, fmt.Errorf("bad syntax for struct tag value of %s: %w", key, err)
+		}
+		if tag != "" && tag[0] != ' ' {
+			return tt, errors.New("struct tag pairs must be separated by spaces")
+		}
+
+		p := strings.Split(value, ",")
+		tt = append(tt, Tag{Key: key, Value: value, Name: p[0], Options: p[1:]})
+	}
+	return tt, nil
+}
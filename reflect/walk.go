@@ -0,0 +1,231 @@
+package reflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Action tells Walk how to carry on once a node is entered.
+type Action int
+
+const (
+	// Continue walks the children of the node.
+	Continue Action = iota
+	// SkipChildren carries on with the next sibling of the node, Leave is still called.
+	SkipChildren
+	// Stop ends the walk, Leave is not called.
+	Stop
+)
+
+// Visitor is called by Walk for every struct field and collection element, depth first.
+//
+// field describes the node, without its children yet, and value is its value, field.Value.
+// The value is settable when Walk was given a pointer and the field is exported (map elements
+// never are): values set in Enter, eg a nil pointer allocated, are walked.
+type Visitor interface {
+	Enter(path string, field *FieldInfo, value reflect.Value) (Action, error)
+	// Leave is called once the children of the node are walked.
+	Leave(path string, field *FieldInfo, value reflect.Value) error
+}
+
+// VisitorFunc is a Visitor only interested in entering nodes.
+type VisitorFunc func(path string, field *FieldInfo, value reflect.Value) (Action, error)
+
+// Enter implements Visitor.
+func (f VisitorFunc) Enter(path string, field *FieldInfo, value reflect.Value) (Action, error) {
+	return f(path, field, value)
+}
+
+// Leave implements Visitor.
+func (f VisitorFunc) Leave(string, *FieldInfo, reflect.Value) error {
+	return nil
+}
+
+// errStop unwinds the walk when a visitor returns Stop.
+var errStop = errors.New("stop")
+
+// Walk walks the data structure v, a struct or a pointer to a struct, as Parse does: the fields of
+// structs, the elements of slices, arrays and maps (sorted by key), pointers and interfaces
+// followed. A value reached again while it is being walked is a cycle, its node has Cycle set and
+// no children. An error returned by the visitor ends the walk and is returned.
+func Walk(v interface{}, vis Visitor) error {
+	rv := reflect.ValueOf(v)
+	w := newWalker(vis)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		w.visiting[visit{ptr: rv.Pointer(), typ: rv.Type()}] = ""
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("input %+v must be a struct or a pointer to a struct", v)
+	}
+
+	_, err := w.fields(rv, "")
+	if errors.Is(err, errStop) {
+		return nil
+	}
+	return err
+}
+
+type (
+	// walker traverses values, building the nodes and calling the visitor, if any, on the way.
+	walker struct {
+		vis Visitor
+		// visiting holds the pointers, maps and slices being traversed with the paths they were
+		// reached at.
+		visiting map[visit]string
+	}
+
+	// visit identifies a pointer, map or slice being traversed. The type tells apart a struct and
+	// its first field, which share their address.
+	visit struct {
+		ptr uintptr
+		typ reflect.Type
+		len int
+	}
+)
+
+func newWalker(vis Visitor) *walker {
+	return &walker{vis: vis, visiting: map[visit]string{}}
+}
+
+// fields walks the fields of a struct value at the path from the top level input.
+func (w *walker) fields(v reflect.Value, path string) ([]*FieldInfo, error) {
+	t := v.Type() // type of the struct, eg Address (=> t.Name() = Address)
+
+	ff := make([]*FieldInfo, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fi := newField(t.Field(i), path)
+		fi.Value = v.Field(i)
+		fi.Elem = elem(fi.Type, fi.Path, map[reflect.Type]string{t: path})
+		ff = append(ff, fi)
+
+		err := w.node(fi)
+		if err != nil {
+			return ff, err
+		}
+	}
+	return ff, nil
+}
+
+// node enters the node, walks its children and leaves it.
+func (w *walker) node(fi *FieldInfo) error {
+	action := Continue
+	if w.vis != nil {
+		var err error
+		action, err = w.vis.Enter(fi.Path, fi, fi.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch action {
+	case Stop:
+		return errStop
+	case Continue:
+		err := w.expand(fi)
+		if err != nil {
+			return err
+		}
+	}
+
+	if w.vis != nil {
+		return w.vis.Leave(fi.Path, fi, fi.Value)
+	}
+	return nil
+}
+
+// expand walks what the value of the node holds: the fields of a struct, the elements of a slice,
+// an array or a map. Pointers and interfaces are followed. A value already being traversed by
+// an enclosing node is recorded as a cycle instead.
+func (w *walker) expand(fi *FieldInfo) error {
+	var entered []visit
+	defer func() {
+		// values shared rather than cyclic are traversed every time they are reached.
+		for _, k := range entered {
+			delete(w.visiting, k)
+		}
+	}()
+	enter := func(v reflect.Value) bool {
+		k := visit{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			k.len = v.Len()
+		}
+		if p, ok := w.visiting[k]; ok {
+			fi.Cycle, fi.CyclePath = v.Type(), p
+			if v.Kind() == reflect.Ptr {
+				fi.Cycle = v.Type().Elem()
+			}
+			return false
+		}
+		w.visiting[k] = fi.Path
+		entered = append(entered, k)
+		return true
+	}
+
+	v := fi.Value
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr && !enter(v) {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.Struct:
+		fi.Children, err = w.fields(v, fi.Path)
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || !enter(v)) {
+			return nil
+		}
+		fi.Children = make([]*FieldInfo, 0, v.Len())
+		for i := 0; i < v.Len() && err == nil; i++ {
+			e := newElem(v.Index(i), fmt.Sprintf("[%d]", i), fi)
+			e.Index = i
+			fi.Children = append(fi.Children, e)
+			err = w.node(e)
+		}
+
+	case reflect.Map:
+		if v.IsNil() || !enter(v) {
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keyName(keys[i]) < keyName(keys[j])
+		})
+		fi.Children = make([]*FieldInfo, 0, len(keys))
+		for _, k := range keys {
+			e := newElem(v.MapIndex(k), keyName(k), fi)
+			e.Index = -1
+			e.Key = k
+			fi.Children = append(fi.Children, e)
+			err = w.node(e)
+			if err != nil {
+				break
+			}
+		}
+	}
+	return err
+}
+
+// newElem returns the node of an element of the collection held by the node parent.
+func newElem(v reflect.Value, name string, parent *FieldInfo) *FieldInfo {
+	path := parent.Path + name
+	return &FieldInfo{
+		Name:     name,
+		Path:     path,
+		Kind:     v.Kind(),
+		Type:     v.Type(),
+		Exported: parent.Exported,
+		Pointer:  v.Kind() == reflect.Ptr,
+		Value:    v,
+		Elem:     elem(v.Type(), path, map[reflect.Type]string{}),
+	}
+}
@@ -0,0 +1,160 @@
+package reflect_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+// eventRecorder records the nodes entered and left, returning the action set for a path.
+type eventRecorder struct {
+	events  []string
+	actions map[string]refl.Action
+	errs    map[string]error
+}
+
+func (r *eventRecorder) Enter(path string, _ *refl.FieldInfo, _ reflect.Value) (refl.Action, error) {
+	r.events = append(r.events, "enter "+path)
+	if err := r.errs["enter "+path]; err != nil {
+		return refl.Continue, err
+	}
+	return r.actions[path], nil
+}
+
+func (r *eventRecorder) Leave(path string, _ *refl.FieldInfo, _ reflect.Value) error {
+	r.events = append(r.events, "leave "+path)
+	return r.errs["leave "+path]
+}
+
+type walkConfig struct {
+	Name  string
+	DB    walkDB
+	Hosts []string
+	Last  int
+}
+
+type walkDB struct {
+	Host string
+	Port int
+}
+
+func TestWalk(t *testing.T) {
+	cfg := &walkConfig{Hosts: []string{"a", "b"}}
+	errVisit := errors.New("visit failed")
+
+	tests := []struct {
+		name    string
+		actions map[string]refl.Action
+		errs    map[string]error
+		err     error
+		events  string
+	}{
+		{
+			name: "continue",
+			events: "enter Name, leave Name, " +
+				"enter DB, enter DB.Host, leave DB.Host, enter DB.Port, leave DB.Port, leave DB, " +
+				"enter Hosts, enter Hosts[0], leave Hosts[0], enter Hosts[1], leave Hosts[1], leave Hosts, " +
+				"enter Last, leave Last",
+		},
+		{
+			name:    "skip children",
+			actions: map[string]refl.Action{"DB": refl.SkipChildren, "Hosts": refl.SkipChildren},
+			events: "enter Name, leave Name, enter DB, leave DB, enter Hosts, leave Hosts, " +
+				"enter Last, leave Last",
+		},
+		{
+			name:    "stop",
+			actions: map[string]refl.Action{"DB.Host": refl.Stop},
+			events:  "enter Name, leave Name, enter DB, enter DB.Host",
+		},
+		{
+			name:    "stop in a collection",
+			actions: map[string]refl.Action{"Hosts[0]": refl.Stop},
+			events: "enter Name, leave Name, " +
+				"enter DB, enter DB.Host, leave DB.Host, enter DB.Port, leave DB.Port, leave DB, " +
+				"enter Hosts, enter Hosts[0]",
+		},
+		{
+			name:   "enter error",
+			errs:   map[string]error{"enter DB.Port": errVisit},
+			err:    errVisit,
+			events: "enter Name, leave Name, enter DB, enter DB.Host, leave DB.Host, enter DB.Port",
+		},
+		{
+			name: "leave error",
+			errs: map[string]error{"leave DB": errVisit},
+			err:  errVisit,
+			events: "enter Name, leave Name, " +
+				"enter DB, enter DB.Host, leave DB.Host, enter DB.Port, leave DB.Port, leave DB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &eventRecorder{actions: tt.actions, errs: tt.errs}
+			err := refl.Walk(cfg, r)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Walk() error %v, want %v", err, tt.err)
+			}
+			if got := strings.Join(r.events, ", "); got != tt.events {
+				t.Errorf("events\n%s\nwant\n%s", got, tt.events)
+			}
+		})
+	}
+}
+
+func TestWalkSetsValues(t *testing.T) {
+	type config struct {
+		DB   *walkDB
+		Name string
+	}
+
+	cfg := &config{}
+	var paths []string
+	err := refl.Walk(cfg, refl.VisitorFunc(func(path string, f *refl.FieldInfo, v reflect.Value) (refl.Action, error) {
+		paths = append(paths, path)
+		switch path {
+		case "DB":
+			// a pointer allocated on the way in is walked.
+			v.Set(reflect.New(f.Type.Elem()))
+		case "DB.Port":
+			v.SetInt(5432)
+		}
+		return refl.Continue, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"DB", "DB.Host", "DB.Port", "Name"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("walked %v, want %v", paths, want)
+	}
+	if cfg.DB == nil || cfg.DB.Port != 5432 {
+		t.Errorf("DB %+v, want it allocated with port 5432", cfg.DB)
+	}
+}
+
+func TestWalkInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		err   bool
+	}{
+		{name: "struct", input: walkConfig{}},
+		{name: "pointer", input: &walkConfig{}},
+		{name: "nil pointer", input: (*walkConfig)(nil), err: true},
+		{name: "int", input: 1, err: true},
+		{name: "nil", input: nil, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := refl.Walk(tt.input, &eventRecorder{})
+			if (err != nil) != tt.err {
+				t.Errorf("Walk() error %v, want error %t", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package tagcheck
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer runs the checks of Check over the struct types declared in a package, from its source.
+var Analyzer = &analysis.Analyzer{
+	Name: "tagcheck",
+	Doc: `check struct tags read through reflection
+
+The tagcheck analyzer reports malformed tags, unknown env and json options, required together
+with envDefault, env variable names and json keys used twice, env and json tags on unexported
+fields and omitempty on struct values.`,
+	Run: run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	files := map[*token.File]bool{}
+	for _, f := range pass.Files {
+		files[pass.Fset.File(f.Pos())] = true
+	}
+
+	// a type used by several others is checked with each of them, its issues are reported once.
+	type reported struct {
+		pos      token.Pos
+		key, msg string
+	}
+	seen := map[reported]bool{}
+
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		st, ok := tn.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+
+		c := &checker{report: func(f *field, key, msg string) {
+			r := reported{pos: f.pos, key: key, msg: msg}
+			// fields of types declared in other packages are not ours to report.
+			if seen[r] || !files[pass.Fset.File(f.pos)] {
+				return
+			}
+			seen[r] = true
+			pass.Reportf(f.pos, "%s", Issue{Path: name + "." + f.path, Key: key, Message: msg})
+		}}
+		c.check(fromTypes(st, "", map[types.Type]bool{tn.Type(): true}))
+	}
+	return nil, nil
+}
+
+// fromTypes returns the fields of the struct type st. stack holds the struct types being
+// described, a field referring to one of them is a cycle and has no fields.
+func fromTypes(st *types.Struct, path string, stack map[types.Type]bool) []*field {
+	fields := make([]*field, 0, st.NumFields())
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		f := &field{
+			name:     v.Name(),
+			path:     fieldPath(path, v.Name()),
+			pos:      v.Pos(),
+			tag:      reflect.StructTag(st.Tag(i)),
+			exported: v.Exported(),
+			embedded: v.Embedded(),
+		}
+		_, f.structValue = v.Type().Underlying().(*types.Struct)
+		f.fields = nested(v.Type(), f.path, stack)
+
+		// collections of collections hold their structs deeper.
+		t, elemPath := v.Type(), f.path
+		for {
+			e, ok := elem(t)
+			if !ok {
+				break
+			}
+			elemPath += "[]"
+			if f.elem = nested(e, elemPath, stack); len(f.elem) > 0 {
+				break
+			}
+			t = e
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// nested returns the fields of the struct of type t, or t points to.
+func nested(t types.Type, path string, stack map[types.Type]bool) []*field {
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || stack[t] {
+		return nil
+	}
+
+	stack[t] = true
+	defer delete(stack, t)
+	return fromTypes(st, path, stack)
+}
+
+// elem returns the element type of a collection of type t, or t points to.
+func elem(t types.Type) (types.Type, bool) {
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = p.Elem()
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		return u.Elem(), true
+	case *types.Array:
+		return u.Elem(), true
+	case *types.Map:
+		return u.Elem(), true
+	}
+	return nil, false
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,138 @@
+package tagcheck_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tamarakaufler/go-and-reflect/tagcheck"
+	"golang.org/x/tools/go/analysis"
+)
+
+// TestAnalyzer runs the analyzer over the packages in testdata/src, each diagnostic matching a
+// comment // want `regexp` on its line, as golang.org/x/tools/go/analysis/analysistest does.
+func TestAnalyzer(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "src", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			fset := token.NewFileSet()
+			pass, want := load(t, fset, dir)
+			var got []analysis.Diagnostic
+			pass.Report = func(d analysis.Diagnostic) {
+				got = append(got, d)
+			}
+			_, err := tagcheck.Analyzer.Run(pass)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, d := range got {
+				pos := fset.Position(d.Pos)
+				key := lineKey(pos)
+				i := matching(want[key], d.Message)
+				if i < 0 {
+					t.Errorf("%s: unexpected diagnostic: %s", pos, d.Message)
+					continue
+				}
+				want[key] = append(want[key][:i], want[key][i+1:]...)
+			}
+			for key, rr := range want {
+				for _, r := range rr {
+					t.Errorf("%s: no diagnostic matching %s", key, r)
+				}
+			}
+		})
+	}
+}
+
+// load parses and type checks the package in dir, returning a pass over it and the expected
+// diagnostics by file:line.
+func load(t *testing.T, fset *token.FileSet, dir string) (*analysis.Pass, map[string][]*regexp.Regexp) {
+	t.Helper()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []*ast.File
+	for _, p := range pkgs {
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+	}
+	pkg, err := conf.Check(dir, fset, files, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]*regexp.Regexp{}
+	for _, f := range files {
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				text := strings.TrimPrefix(c.Text, "//")
+				if !strings.HasPrefix(strings.TrimSpace(text), "want ") {
+					continue
+				}
+				key := lineKey(fset.Position(c.Pos()))
+				for _, p := range patterns(t, strings.TrimPrefix(strings.TrimSpace(text), "want ")) {
+					want[key] = append(want[key], regexp.MustCompile(p))
+				}
+			}
+		}
+	}
+
+	return &analysis.Pass{
+		Analyzer:  tagcheck.Analyzer,
+		Fset:      fset,
+		Files:     files,
+		Pkg:       pkg,
+		TypesInfo: info,
+	}, want
+}
+
+// patterns returns the quoted regexps following want.
+func patterns(t *testing.T, s string) []string {
+	t.Helper()
+	var pp []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			t.Fatalf("bad want comment %q: %v", s, err)
+		}
+		p, err := strconv.Unquote(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pp = append(pp, p)
+		s = s[len(q):]
+	}
+	return pp
+}
+
+func matching(rr []*regexp.Regexp, msg string) int {
+	for i, r := range rr {
+		if r.MatchString(msg) {
+			return i
+		}
+	}
+	return -1
+}
+
+func lineKey(pos token.Position) string {
+	return pos.Filename + ":" + strconv.Itoa(pos.Line)
+}
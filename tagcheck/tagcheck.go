@@ -0,0 +1,250 @@
+// Package tagcheck reports struct tags that reflection based packages silently ignore or misread,
+// such as env:"DB_HOST,requird" or json:",omitempty" on a struct value:
+//
+//	issues, err := tagcheck.Check(reflect.TypeOf(Config{}))
+//
+// The same checks run statically over the source of a package through Analyzer, see cmd/tagcheck.
+package tagcheck
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+
+	refl "github.com/tamarakaufler/go-and-reflect/reflect"
+)
+
+// Issue is a problem found in the tags of a field.
+type Issue struct {
+	// Path is the path of the field from the checked struct, eg Address.Street, elements of
+	// collections are written Users[].Name.
+	Path string
+	// Key is the tag key the issue is about, eg env, empty for malformed tags.
+	Key     string
+	Message string
+}
+
+// String returns the issue as Path: key: message.
+func (i Issue) String() string {
+	if i.Key == "" {
+		return i.Path + ": " + i.Message
+	}
+	return i.Path + ": " + i.Key + ": " + i.Message
+}
+
+// Check checks the tags of the fields of the struct type t, or of the struct t points to, and of
+// the structs they hold.
+func Check(t reflect.Type) ([]Issue, error) {
+	s, err := refl.Describe(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	c := &checker{report: func(f *field, key, msg string) {
+		issues = append(issues, Issue{Path: f.path, Key: key, Message: msg})
+	}}
+	c.check(fromSchema(s.Fields))
+	return issues, nil
+}
+
+// field is what the checks know of a struct field, described from a reflect.Type or from go/types.
+type field struct {
+	name, path string
+	pos        token.Pos // of the field in the source, when checked statically.
+	tag        reflect.StructTag
+	exported   bool
+	embedded   bool
+	// structValue is true for fields of a struct type, not a pointer.
+	structValue bool
+	// fields are the fields of the struct the field holds or points to, nil when cut by a cycle.
+	fields []*field
+	// elem are the fields of the struct elements of a collection, each element being a JSON
+	// object of its own.
+	elem []*field
+}
+
+// fromSchema returns the fields described by the reflect schema nodes.
+func fromSchema(ff []*refl.FieldInfo) []*field {
+	fields := make([]*field, 0, len(ff))
+	for _, fi := range ff {
+		f := &field{
+			name:        fi.Name,
+			path:        fi.Path,
+			tag:         fi.StructTag,
+			exported:    fi.Exported,
+			embedded:    fi.Embedded,
+			structValue: fi.Kind == reflect.Struct,
+			fields:      fromSchema(fi.Children),
+		}
+		// collections of collections hold their structs deeper.
+		for e := fi.Elem; e != nil; e = e.Elem {
+			if len(e.Children) > 0 {
+				f.elem = fromSchema(e.Children)
+				break
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// options are the options understood after the name of the tags, as env.Parse and encoding/json
+// read them.
+var options = map[string]map[string]bool{
+	"env":  {"required": true, "notEmpty": true, "unset": true, "noOverwrite": true, "secret": true},
+	"json": {"omitempty": true, "omitzero": true, "string": true},
+}
+
+type (
+	checker struct {
+		report func(f *field, key, msg string)
+		// envNames holds the paths of the fields using an env variable name.
+		envNames map[string]string
+	}
+
+	// object holds the fields of a JSON object, by key, with the depth of embedding they are
+	// promoted from. path is the path of the struct encoded as the object.
+	object struct {
+		path   string
+		fields map[string]*jsonField
+	}
+
+	jsonField struct {
+		path  string
+		depth int
+	}
+)
+
+// check checks the fields of a struct tree.
+func (c *checker) check(fields []*field) {
+	c.envNames = map[string]string{}
+	c.fields(fields, "", true, newObject(""), 0)
+}
+
+func newObject(path string) *object {
+	return &object{path: path, fields: map[string]*jsonField{}}
+}
+
+// fields checks the fields of a struct. env is true while the fields are read by env.Parse,
+// which does not look into collections, obj is the JSON object the fields belong to.
+func (c *checker) fields(fields []*field, prefix string, env bool, obj *object, depth int) {
+	for _, f := range fields {
+		c.tags(f)
+		if env {
+			c.envName(f, prefix)
+		}
+
+		jsonTag, hasJSON := f.tag.Lookup("json")
+		jsonName := strings.Split(jsonTag, ",")[0]
+		switch {
+		case jsonTag == "-" || (!f.exported && !(f.embedded && len(f.fields) > 0)):
+		case f.embedded && jsonName == "" && len(f.fields) > 0:
+			// the fields of embedded structs are promoted to the object, unless named in the tag.
+			c.fields(f.fields, prefix+f.tag.Get("envPrefix"), env, obj, depth+1)
+			c.elem(f)
+			continue
+		default:
+			if !hasJSON || jsonName == "" {
+				jsonName = f.name
+			}
+			c.jsonKey(f, jsonName, obj, depth)
+		}
+
+		if len(f.fields) > 0 {
+			c.fields(f.fields, prefix+f.tag.Get("envPrefix"), env, newObject(f.path), 0)
+		}
+		c.elem(f)
+	}
+}
+
+// elem checks the structs held by a collection, which env.Parse does not read.
+func (c *checker) elem(f *field) {
+	if len(f.elem) > 0 {
+		c.fields(f.elem, "", false, newObject(f.path+"[]"), 0)
+	}
+}
+
+// tags checks the tags of a field on their own.
+func (c *checker) tags(f *field) {
+	err := refl.ValidateTag(f.tag)
+	if err != nil {
+		c.report(f, "", "malformed tag: "+err.Error())
+	}
+
+	for _, t := range refl.ParseTags(f.tag) {
+		if known, ok := options[t.Key]; ok {
+			for _, o := range t.Options {
+				o = strings.TrimSpace(o)
+				if o != "" && !known[o] {
+					c.report(f, t.Key, fmt.Sprintf("unknown option %q", o))
+				}
+			}
+		}
+		if !f.exported && (t.Key == "json" || strings.HasPrefix(t.Key, "env")) {
+			c.report(f, t.Key, "tag on an unexported field has no effect")
+		}
+	}
+
+	env, ok := f.tag.Lookup("env")
+	if _, hasDefault := f.tag.Lookup("envDefault"); ok && hasDefault && hasOption(env, "required") {
+		c.report(f, "env", "required has no effect together with envDefault")
+	}
+	if json, ok := f.tag.Lookup("json"); ok && f.structValue && hasOption(json, "omitempty") {
+		c.report(f, "json", "omitempty has no effect on a struct value, use a pointer")
+	}
+}
+
+// envName reports env variable names already used in the tree, envPrefix of the enclosing
+// structs prepended.
+func (c *checker) envName(f *field, prefix string) {
+	if !f.exported {
+		return
+	}
+	env, ok := f.tag.Lookup("env")
+	if !ok {
+		return
+	}
+	names := []string{strings.TrimSpace(strings.Split(env, ",")[0])}
+	for _, n := range strings.Split(f.tag.Get("envAliases"), ",") {
+		names = append(names, strings.TrimSpace(n))
+	}
+
+	for _, n := range names {
+		if n == "" {
+			continue
+		}
+		n = prefix + n
+		if p, ok := c.envNames[n]; ok && p != f.path {
+			c.report(f, "env", fmt.Sprintf("%s is also read into %s", n, p))
+			continue
+		}
+		c.envNames[n] = f.path
+	}
+}
+
+// jsonKey reports JSON keys used twice in an object at the same depth of embedding, which
+// encoding/json then leaves out. The other field is named by its path within the object.
+func (c *checker) jsonKey(f *field, key string, obj *object, depth int) {
+	prev, ok := obj.fields[key]
+	switch {
+	case !ok || depth < prev.depth:
+		obj.fields[key] = &jsonField{path: f.path, depth: depth}
+	case depth == prev.depth:
+		other := prev.path
+		if obj.path != "" {
+			other = strings.TrimPrefix(other, obj.path+".")
+		}
+		c.report(f, "json", fmt.Sprintf("key %q is also used by %s", key, other))
+	}
+}
+
+func hasOption(value, opt string) bool {
+	for _, o := range strings.Split(value, ",")[1:] {
+		if strings.TrimSpace(o) == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package tagcheck_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tamarakaufler/go-and-reflect/tagcheck"
+)
+
+type (
+	checkDB struct {
+		Host string `env:"HOST"`
+	}
+
+	checkItem struct {
+		ID string `json:"id"`
+	}
+)
+
+// TestCheck checks a type breaking each rule. Types whose tags go vet reports are built with
+// reflect.StructOf.
+func TestCheck(t *testing.T) {
+	str := reflect.TypeOf("")
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want []string
+	}{
+		{
+			name: "malformed tag",
+			typ: reflect.StructOf([]reflect.StructField{
+				{Name: "Name", Type: str, Tag: `json:name`},
+				{Name: "Port", Type: str, Tag: `env:"PORT"`},
+			}),
+			want: []string{`Name: malformed tag: bad syntax for struct tag value of json, it must be double quoted`},
+		},
+		{
+			name: "unknown option",
+			typ: reflect.TypeOf(struct {
+				Host string `env:"DB_HOST,requird"`
+				Name string `json:"name,omitemtpy"`
+				Addr string `env:"ADDR,required,unset"`
+			}{}),
+			want: []string{
+				`Host: env: unknown option "requird"`,
+				`Name: json: unknown option "omitemtpy"`,
+			},
+		},
+		{
+			name: "required with envDefault",
+			typ: reflect.TypeOf(struct {
+				Level string `env:"LEVEL,required" envDefault:"info"`
+				Port  int    `env:"PORT" envDefault:"8080"`
+			}{}),
+			want: []string{`Level: env: required has no effect together with envDefault`},
+		},
+		{
+			name: "duplicate env name",
+			typ: reflect.TypeOf(struct {
+				Host    string  `env:"HOST"`
+				Addr    string  `env:"HOST"`
+				Timeout string  `env:"TIMEOUT" envAliases:"HOST"`
+				DB      checkDB `envPrefix:"DB_"`
+				Replica checkDB `envPrefix:"DB_"`
+			}{}),
+			want: []string{
+				`Addr: env: HOST is also read into Host`,
+				`Timeout: env: HOST is also read into Host`,
+				`Replica.Host: env: DB_HOST is also read into DB.Host`,
+			},
+		},
+		{
+			name: "duplicate json key",
+			typ: reflect.StructOf([]reflect.StructField{
+				{Name: "Name", Type: str, Tag: `json:"name"`},
+				{Name: "Title", Type: str, Tag: `json:"name"`},
+				{Name: "Items", Type: reflect.TypeOf([]checkItem{}), Tag: `json:"items"`},
+				{Name: "Keys", Type: reflect.SliceOf(reflect.StructOf([]reflect.StructField{
+					{Name: "ID", Type: str, Tag: `json:"id"`},
+					{Name: "Key", Type: str, Tag: `json:"id"`},
+				})), Tag: `json:"keys"`},
+			}),
+			want: []string{
+				`Title: json: key "name" is also used by Name`,
+				`Keys[].Key: json: key "id" is also used by ID`,
+			},
+		},
+		{
+			name: "tag on an unexported field",
+			typ: reflect.TypeOf(struct {
+				host string `env:"HOST"`
+				size int    `yaml:"size"`
+			}{}),
+			want: []string{`host: env: tag on an unexported field has no effect`},
+		},
+		{
+			name: "omitempty on a struct value",
+			typ: reflect.TypeOf(struct {
+				Created time.Time  `json:"created,omitempty"`
+				Updated *time.Time `json:"updated,omitempty"`
+				Deleted time.Time  `json:"deleted,omitzero"`
+			}{}),
+			want: []string{`Created: json: omitempty has no effect on a struct value, use a pointer`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := tagcheck.Check(tt.typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(issues))
+			for _, i := range issues {
+				got = append(got, i.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	_, err := tagcheck.Check(reflect.TypeOf(0))
+	if err == nil {
+		t.Error("Check(int) returned no error")
+	}
+}
@@ -0,0 +1,64 @@
+// Package rules holds struct types breaking each of the tagcheck rules, the diagnostics expected
+// are written in want comments.
+package rules
+
+import "time"
+
+type Malformed struct {
+	Name string `json:name`  // want `Malformed.Name: malformed tag: .*`
+	Port int    `env:"PORT"` // a well formed tag.
+}
+
+type UnknownOption struct {
+	Host string `env:"DB_HOST,requird"`     // want `UnknownOption.Host: env: unknown option "requird"`
+	Name string `json:"name,omitemtpy"`     // want `UnknownOption.Name: json: unknown option "omitemtpy"`
+	Addr string `env:"ADDR,required,unset"` // known options.
+}
+
+type RequiredDefault struct {
+	Level string `env:"LEVEL,required" envDefault:"info"` // want `RequiredDefault.Level: env: required has no effect together with envDefault`
+	Port  int    `env:"PORT" envDefault:"8080"`
+}
+
+type DuplicateEnv struct {
+	Host    string `env:"HOST"`
+	Addr    string `env:"HOST"`                      // want `DuplicateEnv.Addr: env: HOST is also read into Host`
+	Timeout string `env:"TIMEOUT" envAliases:"HOST"` // want `DuplicateEnv.Timeout: env: HOST is also read into Host`
+	DB      DB     `envPrefix:"DB_"`
+	Replica DB     `envPrefix:"DB_"`
+}
+
+// the fields of structs held twice are reported where they are declared.
+type DB struct {
+	Host string `env:"HOST"` // want `DuplicateEnv.Replica.Host: env: DB_HOST is also read into DB.Host`
+}
+
+type DuplicateJSON struct {
+	Name  string `json:"name"`
+	Title string `json:"name"` // want `DuplicateJSON.Title: json: key "name" is also used by Name`
+	Base
+	Items []Item `json:"items"`
+}
+
+type Base struct {
+	ID string `json:"id"`
+	// promoted at a lower depth, Name hides it.
+	Other string `json:"name"`
+}
+
+type Item struct {
+	ID  string `json:"id"`
+	Key string `json:"id"` // want `DuplicateJSON.Items\[\].Key: json: key "id" is also used by ID`
+}
+
+type Unexported struct {
+	host string `env:"HOST"`  // want `Unexported.host: env: tag on an unexported field has no effect`
+	name string `json:"name"` // want `Unexported.name: json: tag on an unexported field has no effect`
+	size int    `yaml:"size"` // other keys may be read by the package itself.
+}
+
+type OmitemptyStruct struct {
+	Created time.Time  `json:"created,omitempty"` // want `OmitemptyStruct.Created: json: omitempty has no effect on a struct value, use a pointer`
+	Updated *time.Time `json:"updated,omitempty"`
+	Deleted time.Time  `json:"deleted,omitzero"`
+}